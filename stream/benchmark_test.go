@@ -310,3 +310,15 @@ func BenchmarkNativeReduce(b *testing.B) {
 		_ = result
 	}
 }
+
+func BenchmarkTopK(b *testing.B) {
+	data := make([]int, 1000)
+	for i := 0; i < 1000; i++ {
+		data[i] = (i * 7919) % 1000
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		OfSlice(data).TopK(10, NaturalOrder[int]())
+	}
+}
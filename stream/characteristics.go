@@ -0,0 +1,129 @@
+package stream
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Characteristic 描述流中元素具有的性质，语义与 Java Spliterator 的特征值相同
+type Characteristic uint
+
+const (
+	// Sized 表示 EstimateSize 返回的是确切的元素个数
+	Sized Characteristic = 1 << iota
+	// Sorted 表示元素按 Characteristics.Comparator 升序排列
+	Sorted
+	// Distinct 表示元素两两不同
+	Distinct
+	// Ordered 表示元素有确定的顺序
+	Ordered
+	// NonNull 表示元素不可能为 nil
+	NonNull
+	// Immutable 表示数据源在流执行期间不会被修改
+	Immutable
+)
+
+var characteristicNames = []string{"SIZED", "SORTED", "DISTINCT", "ORDERED", "NONNULL", "IMMUTABLE"}
+
+func (c Characteristic) String() string {
+	names := make([]string, 0, len(characteristicNames))
+	for i, name := range characteristicNames {
+		if c&(1<<i) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// Characteristics 是流的特征值，Comparator 为 Sorted 时元素的排序依据
+type Characteristics[T any] struct {
+	Flags      Characteristic
+	Comparator Comparator[T]
+	ordering   any
+}
+
+// Has 判断是否具有 flags 中的全部特征
+func (c Characteristics[T]) Has(flags Characteristic) bool {
+	return c.Flags&flags == flags
+}
+
+// Characteristics 返回流经过全部中间操作后的特征值，不会消费流
+func (s *streamImpl[T]) Characteristics() Characteristics[T] {
+	c, _ := s.characterize()
+	return c
+}
+
+// EstimateSize 返回流经过全部中间操作后元素个数的估计值（上限），具有 Sized 特征时为确切值；无法估计时返回 -1
+func (s *streamImpl[T]) EstimateSize() int64 {
+	_, size := s.characterize()
+	return size
+}
+
+// exactSize 在具有 Sized 特征时返回确切的元素个数，否则返回 0，用于预先分配空间
+func (s *streamImpl[T]) exactSize() int {
+	c, size := s.characterize()
+	if !c.Has(Sized) || size < 0 {
+		return 0
+	}
+	return int(size)
+}
+
+// characterize 从数据源的特征值出发，依次推导每个中间操作之后的特征值和元素个数
+func (s *streamImpl[T]) characterize() (Characteristics[T], int64) {
+	c, size := s.sourceCharacteristics(), s.size
+	for _, op := range s.operations {
+		c, size = c.after(op, size)
+	}
+	return c, size
+}
+
+func (s *streamImpl[T]) sourceCharacteristics() Characteristics[T] {
+	c := Characteristics[T]{Flags: s.traits, Comparator: s.sortedBy, ordering: s.ordering}
+	if !nillable[T]() {
+		c.Flags |= NonNull
+	}
+	return c
+}
+
+// after 推导经过中间操作 op 之后的特征值和元素个数，size 为 -1 表示无法估计
+func (c Characteristics[T]) after(op stage[T], size int64) (Characteristics[T], int64) {
+	switch op.name {
+	case "Filter", "SampleFraction", "Throttle", "Debounce", "SampleInterval":
+		c.Flags &^= Sized
+	case "Map", "CumulativeSum":
+		c.Flags &^= Sorted | Distinct
+		c.Comparator, c.ordering = nil, nil
+	case "Limit":
+		if size < 0 || op.n < size {
+			size = max(op.n, 0)
+		}
+	case "Skip":
+		if size >= 0 {
+			size = max(size-max(op.n, 0), 0)
+		}
+	case "Sorted":
+		c.Flags |= Sorted | Ordered
+		c.Comparator, c.ordering = op.comparator, op.ordering
+	case "Distinct":
+		c.Flags = c.Flags&^Sized | Distinct
+	case "Peek", "Log", "RateLimit", "Delay":
+	case "Shuffle":
+		c.Flags &^= Sorted
+		c.Comparator, c.ordering = nil, nil
+	default:
+		c.Flags &= Ordered | NonNull | Immutable
+		c.Comparator, c.ordering = nil, nil
+		size = -1
+	}
+	return c, size
+}
+
+// nillable 判断类型 T 的值是否可能为 nil
+func nillable[T any]() bool {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCharacteristics(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   Stream[int64]
+		expected Characteristic
+		size     int64
+	}{
+		{"range", Range(0, 10), Sized | Sorted | Distinct | Ordered | NonNull | Immutable, 10},
+		{"filter", Range(0, 10).Filter(func(n int64) bool { return n > 3 }), Sorted | Distinct | Ordered | NonNull | Immutable, 10},
+		{"map", Range(0, 10).Map(func(n int64) int64 { return n % 3 }), Sized | Ordered | NonNull | Immutable, 10},
+		{"skip and limit", Range(0, 10).Skip(3).Limit(5), Sized | Sorted | Distinct | Ordered | NonNull | Immutable, 5},
+		{"skip beyond size", Of[int64](1, 2).Skip(5), Sized | Ordered | NonNull, 0},
+		{"distinct", Of[int64](3, 1, 3).Distinct(), Distinct | Ordered | NonNull, 3},
+		{"sorted", Of[int64](3, 1, 2).Sorted(NaturalOrder[int64]()), Sized | Sorted | Ordered | NonNull, 3},
+		{"lazy source", Generate(func() int64 { return 1 }, 4).Filter(func(int64) bool { return true }).Limit(2), Ordered | NonNull, 2},
+		{"unknown stage", Range(0, 10).FlatMap(func(n int64) Stream[int64] { return Of(n) }), Ordered | NonNull | Immutable, -1},
+		{"concat", Concat(Range(0, 3), Generate(func() int64 { return 1 }, 2)), Sized | Ordered | NonNull, 5},
+	}
+
+	for _, tt := range tests {
+		c := tt.stream.Characteristics()
+		if c.Flags != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, c.Flags)
+		}
+		if size := tt.stream.EstimateSize(); size != tt.size {
+			t.Errorf("%s: expected size %d, got %d", tt.name, tt.size, size)
+		}
+		if c.Has(Sized) && int64(len(tt.stream.ToSlice())) != tt.size {
+			t.Errorf("%s: expected exactly %d elements", tt.name, tt.size)
+		}
+	}
+}
+
+func TestCharacteristicsComparator(t *testing.T) {
+	descending := func(a, b string) int { return strings.Compare(b, a) }
+	c := Of("b", "a", "c").Sorted(descending).Filter(func(s string) bool { return s != "a" }).Characteristics()
+	if !c.Has(Sorted) || c.Comparator == nil || c.Comparator("a", "b") <= 0 {
+		t.Errorf("Expected descending comparator to be carried, got %s", c.Flags)
+	}
+
+	c = Of("b", "a").Sorted(descending).Map(strings.ToUpper).Characteristics()
+	if c.Has(Sorted) || c.Comparator != nil {
+		t.Errorf("Expected Map to clear Sorted, got %s", c.Flags)
+	}
+}
+
+func TestCharacteristicsNonNull(t *testing.T) {
+	if Of[*int](nil).Characteristics().Has(NonNull) {
+		t.Errorf("Expected pointer stream not to be NonNull")
+	}
+	if !Of(struct{}{}).Characteristics().Has(NonNull) {
+		t.Errorf("Expected struct stream to be NonNull")
+	}
+}
+
+func TestPresizedCollectors(t *testing.T) {
+	result := Range(0, 100).Map(func(n int64) int64 { return n * 2 }).Skip(10).ToSlice()
+	if len(result) != 90 || cap(result) != 90 {
+		t.Errorf("Expected exactly sized slice of 90, got len %d cap %d", len(result), cap(result))
+	}
+
+	collected := Range(0, 5).Collect(ToSlice[int64]()).([]int64)
+	if len(collected) != 5 || cap(collected) != 5 {
+		t.Errorf("Expected exactly sized slice of 5, got len %d cap %d", len(collected), cap(collected))
+	}
+
+	m := Range(0, 3).Collect(ToMap(func(n int64) int64 { return n }, func(n int64) string { return "v" })).(map[int64]string)
+	if len(m) != 3 {
+		t.Errorf("Expected 3 entries, got %v", m)
+	}
+}
+
+func TestDistinctSourceDropsDistinct(t *testing.T) {
+	plan := Range(0, 5).Filter(func(n int64) bool { return n > 1 }).Distinct().Explain()
+	if plan != "Range\nFilter\n" {
+		t.Errorf("Expected Distinct to be dropped, got:\n%s", plan)
+	}
+}
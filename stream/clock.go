@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock 是与时间相关的操作所使用的时钟，测试中可以用 VirtualClock 代替真实时间
+type Clock interface {
+	Now() time.Time
+	// Sleep 等待 d 或者 ctx 结束，ctx 先结束时返回 ctx.Err()
+	Sleep(ctx context.Context, d time.Duration) error
+	// AfterFunc 在 d 之后调用 f，stop 取消尚未执行的调用并返回是否取消成功
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+type systemClock struct{}
+
+// SystemClock 返回使用真实时间的时钟
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (systemClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// VirtualClock 是只在 Sleep 和 Advance 时前进的时钟：Sleep 立即返回，并按时间顺序同步执行期间到期的 AfterFunc，
+// 使依赖时间的流水线在测试中不需要真正等待
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+	seq    int64
+}
+
+type virtualTimer struct {
+	when time.Time
+	seq  int64
+	f    func()
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Sleep 把时间前进 d；期间执行的 AfterFunc 使 ctx 结束时停在该时刻并返回 ctx.Err()
+func (c *VirtualClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.advanceTo(c.Now().Add(d), ctx)
+	return ctx.Err()
+}
+
+// Advance 把时间前进 d，并执行期间到期的 AfterFunc
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.advanceTo(c.Now().Add(d), nil)
+}
+
+func (c *VirtualClock) AfterFunc(d time.Duration, f func()) func() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seq++
+	t := &virtualTimer{when: c.now.Add(d), seq: c.seq, f: f}
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, pending := range c.timers {
+			if pending == t {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// advanceTo 依次执行不晚于 target 的定时器，执行时不持有锁，因此回调中可以再次使用时钟
+func (c *VirtualClock) advanceTo(target time.Time, ctx context.Context) {
+	for {
+		c.mu.Lock()
+		next := -1
+		for i, t := range c.timers {
+			if !t.when.After(target) && (next < 0 || t.when.Before(c.timers[next].when) ||
+				t.when.Equal(c.timers[next].when) && t.seq < c.timers[next].seq) {
+				next = i
+			}
+		}
+		if next < 0 {
+			if c.now.Before(target) {
+				c.now = target
+			}
+			c.mu.Unlock()
+			return
+		}
+		t := c.timers[next]
+		c.timers = append(c.timers[:next], c.timers[next+1:]...)
+		if c.now.Before(t.when) {
+			c.now = t.when
+		}
+		c.mu.Unlock()
+
+		t.f()
+		if ctx != nil && ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// withClockTimeout 返回在时钟经过 d 后以 cause 取消的 context
+func withClockTimeout(ctx context.Context, clock Clock, d time.Duration, cause error) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := clock.AfterFunc(d, func() { cancel(cause) })
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
@@ -9,6 +9,12 @@ type Collector[T, A, R any] interface {
 	Collect(items []T) any
 }
 
+// iteratorCollector 由可以边拉取边累积的收集器实现，Collect 时无需先把流物化为切片；
+// sizeHint 为已知的确切元素个数，未知时为 0
+type iteratorCollector[T any] interface {
+	collectFrom(it iterator[T], sizeHint int) any
+}
+
 type sliceCollector[T any] struct{}
 
 func (c sliceCollector[T]) Collect(items []T) any {
@@ -17,6 +23,10 @@ func (c sliceCollector[T]) Collect(items []T) any {
 	return result
 }
 
+func (c sliceCollector[T]) collectFrom(it iterator[T], sizeHint int) any {
+	return drain(it, sizeHint)
+}
+
 func ToSlice[T any]() Collector[T, any, []T] {
 	return sliceCollector[T]{}
 }
@@ -27,8 +37,12 @@ type mapCollector[T any, K comparable, V any] struct {
 }
 
 func (c mapCollector[T, K, V]) Collect(items []T) any {
-	result := make(map[K] V)
-	for _, item := range items {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c mapCollector[T, K, V]) collectFrom(it iterator[T], sizeHint int) any {
+	result := make(map[K]V, sizeHint)
+	for item, ok := it(); ok; item, ok = it() {
 		key := c.keyMapper(item)
 		value := c.valueMapper(item)
 		result[key] = value
@@ -48,8 +62,12 @@ type groupingCollector[T any, K comparable] struct {
 }
 
 func (c groupingCollector[T, K]) Collect(items []T) any {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c groupingCollector[T, K]) collectFrom(it iterator[T], sizeHint int) any {
 	result := make(map[K][]T)
-	for _, item := range items {
+	for item, ok := it(); ok; item, ok = it() {
 		key := c.keyMapper(item)
 		result[key] = append(result[key], item)
 	}
@@ -68,6 +86,14 @@ func (c countingCollector[T]) Collect(items []T) any {
 	return int64(len(items))
 }
 
+func (c countingCollector[T]) collectFrom(it iterator[T], sizeHint int) any {
+	var count int64
+	for _, ok := it(); ok; _, ok = it() {
+		count++
+	}
+	return count
+}
+
 func Counting[T any]() Collector[T, any, int64] {
 	return countingCollector[T]{}
 }
@@ -77,8 +103,12 @@ type summingCollector[T any] struct {
 }
 
 func (c summingCollector[T]) Collect(items []T) any {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c summingCollector[T]) collectFrom(it iterator[T], sizeHint int) any {
 	var sum int64
-	for _, item := range items {
+	for item, ok := it(); ok; item, ok = it() {
 		sum += c.mapper(item)
 	}
 	return sum
@@ -95,14 +125,19 @@ type averagingCollector[T any] struct {
 }
 
 func (c averagingCollector[T]) Collect(items []T) any {
-	if len(items) == 0 {
-		return 0.0
-	}
-	var sum int64
-	for _, item := range items {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c averagingCollector[T]) collectFrom(it iterator[T], sizeHint int) any {
+	var sum, count int64
+	for item, ok := it(); ok; item, ok = it() {
 		sum += c.mapper(item)
+		count++
+	}
+	if count == 0 {
+		return 0.0
 	}
-	return float64(sum) / float64(len(items))
+	return float64(sum) / float64(count)
 }
 
 func Averaging[T any](mapper Function[T, int64]) Collector[T, any, float64] {
@@ -157,8 +192,12 @@ func JoiningWithPrefixSuffix[T any](mapper Function[T, string], delimiter, prefi
 type setCollector[T comparable] struct{}
 
 func (c setCollector[T]) Collect(items []T) any {
-	result := make(map[T]struct{})
-	for _, item := range items {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c setCollector[T]) collectFrom(it iterator[T], sizeHint int) any {
+	result := make(map[T]struct{}, sizeHint)
+	for item, ok := it(); ok; item, ok = it() {
 		result[item] = struct{}{}
 	}
 	return result
@@ -0,0 +1,98 @@
+package stream
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+)
+
+type concurrentResult[R any] struct {
+	seq      int64
+	value    R
+	err      error
+	panicked *goroutinePanic
+}
+
+// MapConcurrent 在最多 concurrency 个 goroutine 中并发调用 fn，适合 I/O 密集的映射；
+// ordered 为 true 时按输入顺序产生结果，否则按完成顺序产生。
+// 任意一次调用失败时流随之结束，Err 返回该错误，并取消传给其余调用的 ctx；
+// fn 中的 panic 在产生对应结果时重新抛出，OnPanic 跳过该结果后继续产生其余结果
+func MapConcurrent[T, R any](ctx context.Context, s Stream[T], fn func(context.Context, T) (R, error), concurrency int, ordered bool) Stream[R] {
+	concurrency = max(concurrency, 1)
+	out := derive("MapConcurrent", s, func(out *streamImpl[R], upstream iterator[T]) iterator[R] {
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		out.onClose(func() error {
+			cancel()
+			wg.Wait()
+			return nil
+		})
+
+		// 已经开始但还没有产生的元素不超过 concurrency 个，因此向 results 发送永远不会阻塞
+		results := make(chan concurrentResult[R], concurrency)
+		reorder := make(map[int64]concurrentResult[R])
+		var started, received, emitted int64
+		exhausted, finished := false, false
+		emit := func(r concurrentResult[R]) (R, bool) {
+			emitted++
+			if r.panicked != nil {
+				// 在调用方的 goroutine 中重新 panic，使 Recover 和 OnPanic 可以处理；调用栈为 fn 中发生 panic 时的调用栈
+				panic(r.panicked)
+			}
+			return r.value, true
+		}
+		return func() (R, bool) {
+			var zero R
+			for !finished {
+				for !exhausted && started-emitted < int64(concurrency) {
+					item, ok := upstream()
+					if !ok {
+						exhausted = true
+						break
+					}
+					wg.Add(1)
+					go func(seq int64, item T) {
+						defer wg.Done()
+						r := concurrentResult[R]{seq: seq}
+						defer func() {
+							if p := recover(); p != nil {
+								r.panicked = &goroutinePanic{value: p, stack: debug.Stack()}
+							}
+							results <- r
+						}()
+						r.value, r.err = fn(ctx, item)
+					}(started, item)
+					started++
+				}
+
+				if r, ok := reorder[emitted]; ok {
+					delete(reorder, emitted)
+					return emit(r)
+				}
+				if received == started {
+					finished = true
+					break
+				}
+
+				r := <-results
+				received++
+				if r.err != nil {
+					finished = true
+					cancel()
+					out.fail(r.err)
+					break
+				}
+				if ordered {
+					reorder[r.seq] = r
+					continue
+				}
+				return emit(r)
+			}
+			return zero, false
+		}
+	})
+	out.origin.parallelism = concurrency
+	// 发生 panic 的调用只影响它自己的结果，跳过后从下一个结果继续
+	out.resumesAfterPanic = true
+	return out
+}
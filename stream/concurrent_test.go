@@ -0,0 +1,155 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMapConcurrentOrdered(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	square := func(ctx context.Context, n int) (int, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		// 较小的元素更慢完成，使完成顺序与输入顺序不同
+		time.Sleep(time.Duration(20-n) * 100 * time.Microsecond)
+		return n * n, nil
+	}
+
+	result := MapConcurrent(context.Background(), RangeStep(0, 20, 1), square, 4, true).ToSlice()
+	expected := RangeStep(0, 20, 1).Map(func(n int) int { return n * n }).ToSlice()
+	if !equalSlices(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+	if peak.Load() > 4 {
+		t.Errorf("Expected at most 4 concurrent calls, got %d", peak.Load())
+	}
+}
+
+func TestMapConcurrentUnordered(t *testing.T) {
+	release := make(chan struct{})
+	wait := func(ctx context.Context, n int) (int, error) {
+		if n == 0 {
+			<-release
+		}
+		return n, nil
+	}
+	s := MapConcurrent(context.Background(), Of(0, 1, 2), wait, 3, false)
+	it := s.iterate()
+	defer s.close()
+
+	first, _ := it()
+	second, _ := it()
+	close(release)
+	third, _ := it()
+	if first == 0 || second == 0 || third != 0 {
+		t.Errorf("Expected the blocked element last, got %d %d %d", first, second, third)
+	}
+	if _, ok := it(); ok {
+		t.Errorf("Expected stream to end")
+	}
+}
+
+func TestMapConcurrentErrorCancels(t *testing.T) {
+	failure := errors.New("fetch failed")
+	var cancelled atomic.Int32
+	fetch := func(ctx context.Context, n int) (int, error) {
+		if n == 3 {
+			return 0, failure
+		}
+		<-ctx.Done()
+		cancelled.Add(1)
+		return 0, ctx.Err()
+	}
+
+	s := MapConcurrent(context.Background(), Of(1, 2, 3, 4, 5), fetch, 3, true)
+	if result := s.ToSlice(); len(result) != 0 {
+		t.Errorf("Expected no results, got %v", result)
+	}
+	if !errors.Is(s.Err(), failure) {
+		t.Errorf("Expected fetch failed, got %v", s.Err())
+	}
+	if cancelled.Load() != 2 {
+		t.Errorf("Expected the 2 outstanding calls to be cancelled, got %d", cancelled.Load())
+	}
+}
+
+func TestMapConcurrentEarlyClose(t *testing.T) {
+	var calls, running atomic.Int32
+	fetch := func(ctx context.Context, n int64) (int64, error) {
+		calls.Add(1)
+		running.Add(1)
+		defer running.Add(-1)
+		return n, nil
+	}
+	first := MapConcurrent(context.Background(), Range(0, 1e9), fetch, 8, true).Limit(2).ToSlice()
+	if !equalSlices(first, []int64{0, 1}) {
+		t.Errorf("Expected [0 1], got %v", first)
+	}
+	if calls.Load() > 10 || running.Load() != 0 {
+		t.Errorf("Expected bounded calls and no running goroutines, got %d calls, %d running", calls.Load(), running.Load())
+	}
+}
+
+func TestMapConcurrentPanicAndExplain(t *testing.T) {
+	s := MapConcurrent(context.Background(), Of(1, 0), func(_ context.Context, n int) (int, error) {
+		return 1 / n, nil
+	}, 2, true).Recover()
+	s.ToSlice()
+	var pe *PanicError
+	if !errors.As(s.Err(), &pe) || pe.Stage != "MapConcurrent" {
+		t.Fatalf("Expected panic to be recovered in the caller, got %v", s.Err())
+	}
+	if !strings.Contains(string(pe.Stack), "TestMapConcurrentPanicAndExplain.func1") {
+		t.Errorf("Expected the stack of the panicking call, got:\n%s", pe.Stack)
+	}
+	if fmt.Sprint(pe.Value) != "runtime error: integer divide by zero" {
+		t.Errorf("Expected the original panic value, got %v", pe.Value)
+	}
+
+	plan := MapConcurrent(context.Background(), Of(1), func(_ context.Context, n int) (int, error) { return n, nil }, 4, false).Explain()
+	if !strings.Contains(plan, "MapConcurrent [parallel=4]") {
+		t.Errorf("Expected parallelism in plan, got:\n%s", plan)
+	}
+}
+
+func TestMapConcurrentSkipPanic(t *testing.T) {
+	for _, ordered := range []bool{true, false} {
+		var indexes []int64
+		s := MapConcurrent(context.Background(), Of(1, 2, 3, 4, 5), func(_ context.Context, n int) (int, error) {
+			if n == 2 {
+				panic("bad element")
+			}
+			return n, nil
+		}, 1, ordered).OnPanic(func(err *PanicError) PanicAction[int] {
+			indexes = append(indexes, err.Index)
+			return SkipOnPanic[int]()
+		})
+		if result := s.ToSlice(); !equalSlices(result, []int{1, 3, 4, 5}) {
+			t.Errorf("ordered=%v: expected [1 3 4 5], got %v", ordered, result)
+		}
+		if s.Err() != nil || !equalSlices(indexes, []int64{1}) {
+			t.Errorf("ordered=%v: expected one skipped result at index 1 and no error, got %v, %v", ordered, indexes, s.Err())
+		}
+	}
+
+	fallback := MapConcurrent(context.Background(), Of(1, 2, 3), func(_ context.Context, n int) (int, error) {
+		if n == 2 {
+			panic("bad element")
+		}
+		return n, nil
+	}, 3, true).OnPanic(func(*PanicError) PanicAction[int] { return FallbackOnPanic(-1) })
+	if result := fallback.ToSlice(); !equalSlices(result, []int{1, -1, 3}) {
+		t.Errorf("Expected [1 -1 3], got %v", result)
+	}
+}
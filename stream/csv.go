@@ -0,0 +1,231 @@
+package stream
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// CSVRowError 描述某一行解码失败的原因，Line 为该记录在输入中的起始行号
+type CSVRowError struct {
+	Line   int
+	Column string
+	Value  string
+	Err    error
+}
+
+func (e *CSVRowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("csv line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("csv line %d, column %q: cannot parse %q: %v", e.Line, e.Column, e.Value, e.Err)
+}
+
+func (e *CSVRowError) Unwrap() error {
+	return e.Err
+}
+
+type CSVOption func(*csvConfig)
+
+type csvConfig struct {
+	comma      rune
+	header     bool
+	timeLayout string
+	onError    func(*CSVRowError)
+}
+
+// WithCSVComma 设置字段分隔符，默认为 ','
+func WithCSVComma(comma rune) CSVOption {
+	return func(c *csvConfig) {
+		c.comma = comma
+	}
+}
+
+// WithCSVHeader 设置是否存在表头，默认 true；没有表头时按结构体字段声明顺序逐列映射
+func WithCSVHeader(header bool) CSVOption {
+	return func(c *csvConfig) {
+		c.header = header
+	}
+}
+
+// WithCSVTimeLayout 设置 time.Time 字段的格式，默认为 time.RFC3339
+func WithCSVTimeLayout(layout string) CSVOption {
+	return func(c *csvConfig) {
+		c.timeLayout = layout
+	}
+}
+
+// OnCSVRowError 设置逐行错误处理函数，设置后解码失败的行会被跳过并交给 handler，否则流在第一个错误处停止
+func OnCSVRowError(handler func(*CSVRowError)) CSVOption {
+	return func(c *csvConfig) {
+		c.onError = handler
+	}
+}
+
+func newCSVConfig(opts []CSVOption) csvConfig {
+	cfg := csvConfig{
+		comma:      ',',
+		header:     true,
+		timeLayout: time.RFC3339,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// FromCSV 惰性地把 CSV 记录解码为 T，列通过 `csv:"column"` 标签与字段对应
+func FromCSV[T any](r io.Reader, opts ...CSVOption) Stream[T] {
+	return newLazyStream("FromCSV", func(s *streamImpl[T]) iterator[T] {
+		cfg := newCSVConfig(opts)
+		fields, err := structFields(reflect.TypeOf((*T)(nil)).Elem(), "csv")
+		if err != nil {
+			s.fail(err)
+			return emptyIterator[T]()
+		}
+
+		reader := csv.NewReader(r)
+		reader.Comma = cfg.comma
+		reader.FieldsPerRecord = -1
+
+		// columns[i] 为第 i 列对应的字段，nil 表示该列没有匹配的字段
+		columns := make([]*structField, 0, len(fields))
+		names := make([]string, 0, len(fields))
+		if cfg.header {
+			header, err := reader.Read()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					s.fail(err)
+				}
+				return emptyIterator[T]()
+			}
+			for _, column := range header {
+				if f, ok := lookupField(fields, column); ok {
+					columns = append(columns, &f)
+				} else {
+					columns = append(columns, nil)
+				}
+				names = append(names, column)
+			}
+		} else {
+			for i := range fields {
+				columns = append(columns, &fields[i])
+				names = append(names, fields[i].name)
+			}
+		}
+
+		return func() (T, bool) {
+			var zero T
+			for {
+				record, err := reader.Read()
+				if errors.Is(err, io.EOF) {
+					return zero, false
+				}
+				if err != nil {
+					rowErr := &CSVRowError{Err: err}
+					var parseErr *csv.ParseError
+					if errors.As(err, &parseErr) {
+						rowErr.Line = parseErr.StartLine
+					}
+					if !s.csvRowFailed(cfg, rowErr) {
+						return zero, false
+					}
+					continue
+				}
+				line, _ := reader.FieldPos(0)
+
+				var item T
+				value := reflect.ValueOf(&item).Elem()
+				var rowErr *CSVRowError
+				for i, text := range record {
+					if i >= len(columns) || columns[i] == nil {
+						continue
+					}
+					if err := parseInto(value.FieldByIndex(columns[i].index), text, cfg.timeLayout); err != nil {
+						rowErr = &CSVRowError{Line: line, Column: names[i], Value: text, Err: err}
+						break
+					}
+				}
+				if rowErr == nil {
+					return item, true
+				}
+				if !s.csvRowFailed(cfg, rowErr) {
+					return zero, false
+				}
+			}
+		}
+	})
+}
+
+// csvRowFailed 处理行错误，返回 true 表示跳过该行继续读取
+func (s *streamImpl[T]) csvRowFailed(cfg csvConfig, err *CSVRowError) bool {
+	if cfg.onError != nil {
+		cfg.onError(err)
+		return true
+	}
+	s.fail(err)
+	return false
+}
+
+// WriteCSV 将流中的结构体逐行写入 w，表头取自 `csv` 标签或字段名
+func WriteCSV[T any](s Stream[T], w io.Writer, opts ...CSVOption) error {
+	cfg := newCSVConfig(opts)
+	fields, err := structFields(reflect.TypeOf((*T)(nil)).Elem(), "csv")
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = cfg.comma
+	if cfg.header {
+		header := make([]string, len(fields))
+		for i, f := range fields {
+			header[i] = f.name
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+	}
+
+	it := s.iterate()
+	defer s.close()
+	record := make([]string, len(fields))
+	for item, ok := it(); ok; item, ok = it() {
+		value := reflect.ValueOf(item)
+		for i, f := range fields {
+			text, err := formatValue(value.FieldByIndex(f.index), cfg.timeLayout)
+			if err != nil {
+				return fmt.Errorf("csv column %q: %w", f.name, err)
+			}
+			record[i] = text
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return s.Err()
+}
+
+type csvCollector[T any] struct {
+	writer io.Writer
+	opts   []CSVOption
+}
+
+func (c csvCollector[T]) Collect(items []T) any {
+	return WriteCSV(OfSlice(items), c.writer, c.opts...)
+}
+
+// ToCSV 收集器将元素写入 writer，Collect 的结果为 error（成功时为 nil）
+func ToCSV[T any](writer io.Writer, opts ...CSVOption) Collector[T, any, error] {
+	return csvCollector[T]{
+		writer: writer,
+		opts:   opts,
+	}
+}
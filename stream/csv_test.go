@@ -0,0 +1,113 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type csvOrder struct {
+	ID       int       `csv:"id"`
+	Customer string    `csv:"customer"`
+	Amount   float64   `csv:"amount"`
+	Paid     bool      `csv:"paid"`
+	Created  time.Time `csv:"created"`
+	Note     string    `csv:"-"`
+}
+
+func TestFromCSV(t *testing.T) {
+	input := "customer,id,amount,paid,created,extra\n" +
+		"alice,1,9.5,true,2024-01-02T03:04:05Z,x\n" +
+		"bob,2,20,false,2024-01-03T00:00:00Z,y\n"
+
+	orders := FromCSV[csvOrder](strings.NewReader(input)).ToSlice()
+	if len(orders) != 2 {
+		t.Fatalf("Expected 2 orders, got %d", len(orders))
+	}
+
+	first := orders[0]
+	if first.ID != 1 || first.Customer != "alice" || first.Amount != 9.5 || !first.Paid {
+		t.Errorf("Unexpected first order: %+v", first)
+	}
+	if !first.Created.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Unexpected created time: %v", first.Created)
+	}
+}
+
+func TestFromCSVPositional(t *testing.T) {
+	type point struct {
+		X int
+		Y int
+	}
+	points := FromCSV[point](strings.NewReader("1;2\n3;4\n"), WithCSVHeader(false), WithCSVComma(';')).ToSlice()
+
+	if len(points) != 2 || points[1].X != 3 || points[1].Y != 4 {
+		t.Errorf("Unexpected points: %+v", points)
+	}
+}
+
+func TestFromCSVRowErrors(t *testing.T) {
+	input := "id,customer,amount\n1,alice,1.5\nx,bob,2\n3,carol,oops\n4,dave,4\n"
+
+	s := FromCSV[csvOrder](strings.NewReader(input))
+	if s.Count() != 1 {
+		t.Errorf("Expected stream to stop at first invalid row")
+	}
+	var rowErr *CSVRowError
+	if !errors.As(s.Err(), &rowErr) || rowErr.Line != 3 || rowErr.Column != "id" {
+		t.Errorf("Expected row error at line 3 column id, got %v", s.Err())
+	}
+	if !errors.Is(s.Err(), strconv.ErrSyntax) {
+		t.Errorf("Expected wrapped strconv.ErrSyntax, got %v", s.Err())
+	}
+
+	var reported []int
+	ids := FromCSV[csvOrder](strings.NewReader(input), OnCSVRowError(func(err *CSVRowError) {
+		reported = append(reported, err.Line)
+	})).ToSlice()
+	if len(ids) != 2 {
+		t.Errorf("Expected 2 valid rows, got %d", len(ids))
+	}
+	if !equalSlices(reported, []int{3, 4}) {
+		t.Errorf("Expected errors on lines [3 4], got %v", reported)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	orders := []csvOrder{
+		{ID: 1, Customer: "alice", Amount: 9.5, Paid: true, Created: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, Customer: "bob, jr", Amount: 20},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(OfSlice(orders), &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "id,customer,amount,paid,created\n" +
+		"1,alice,9.5,true,2024-01-02T00:00:00Z\n" +
+		"2,\"bob, jr\",20,false,\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	roundTrip := FromCSV[csvOrder](&buf).ToSlice()
+	if len(roundTrip) != 2 || roundTrip[1].Customer != "bob, jr" {
+		t.Errorf("Unexpected round trip result: %+v", roundTrip)
+	}
+}
+
+func TestCollectToCSV(t *testing.T) {
+	var buf bytes.Buffer
+	result := Of(csvOrder{ID: 7, Customer: "eve"}).Collect(ToCSV[csvOrder](&buf, WithCSVHeader(false)))
+
+	if result != nil {
+		t.Errorf("Expected nil error, got %v", result)
+	}
+	if buf.String() != "7,eve,0,false,\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
@@ -0,0 +1,238 @@
+package stream
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// stageInfo 描述流水线中的一个阶段，用于 Explain 和 ToDOT
+type stageInfo struct {
+	name    string
+	label   string
+	callers *callers
+	// fused 为优化时合并进该阶段的其它阶段的创建位置
+	fused []*callers
+	// barrier 表示阶段需要先收集全部上游元素
+	barrier bool
+	// shortCircuit 表示阶段可能在上游耗尽之前结束
+	shortCircuit bool
+	// parallelism 大于 1 表示阶段会并发执行
+	parallelism int
+}
+
+// planNode 是执行计划中的一个节点，inputs 为合并多个流的数据源（如 Concat）的各个输入
+type planNode struct {
+	stageInfo
+	callSite string
+	inputs   [][]*planNode
+}
+
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+var trackCallSites atomic.Bool
+
+// SetCallSiteTracking 开启后，新创建的数据源和中间操作会记录用户代码中的创建位置，供 Explain 和 ToDOT 输出。
+// 每次记录都需要回溯调用栈，开销约为数百纳秒，默认关闭
+func SetCallSiteTracking(enabled bool) {
+	trackCallSites.Store(enabled)
+}
+
+// callers 记录调用栈，解析推迟到 Explain 时进行
+type callers struct {
+	pcs [8]uintptr
+	n   int
+}
+
+func captureCallers() *callers {
+	if !trackCallSites.Load() {
+		return nil
+	}
+	c := &callers{}
+	c.n = runtime.Callers(3, c.pcs[:])
+	return c
+}
+
+// site 返回调用栈中第一个位于本包之外（测试文件也视为包外）的位置
+func (c *callers) site() string {
+	if c == nil {
+		return ""
+	}
+	frames := runtime.CallersFrames(c.pcs[:c.n])
+	for {
+		frame, more := frames.Next()
+		if filepath.Dir(frame.File) != packageDir || strings.HasSuffix(frame.File, "_test.go") {
+			return fmt.Sprintf("%s:%d", filepath.Base(frame.File), frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+// describeSource 描述名为 name 的数据源
+func describeSource(name string) stageInfo {
+	return stageInfo{name: name, callers: captureCallers()}
+}
+
+// Label 为最近追加的阶段设置标签，还没有中间操作时为数据源设置标签
+func (s *streamImpl[T]) Label(label string) Stream[T] {
+	s.checkNotConsumed()
+	if len(s.operations) == 0 {
+		s.origin.label = label
+	} else {
+		s.operations[len(s.operations)-1].label = label
+	}
+	return s
+}
+
+// planner 是可以描述自身执行计划的流，用于把上游流的计划拼接到派生流之前
+type planner interface {
+	plan() []*planNode
+}
+
+func (s *streamImpl[T]) plan() []*planNode {
+	nodes := make([]*planNode, 0)
+	if s.upstream != nil {
+		nodes = append(nodes, s.upstream.plan()...)
+	}
+	origin, _, ops := s.optimizedPlan()
+	source := newPlanNode(origin)
+	for _, input := range s.inputs {
+		source.inputs = append(source.inputs, input.plan())
+	}
+	nodes = append(nodes, source)
+	for _, op := range ops {
+		nodes = append(nodes, newPlanNode(op.stageInfo))
+	}
+	return nodes
+}
+
+// newPlanNode 创建计划节点，合并后的阶段依次列出每个原始阶段的创建位置
+func newPlanNode(info stageInfo) *planNode {
+	sites := make([]string, 0, len(info.fused)+1)
+	for _, c := range append([]*callers{info.callers}, info.fused...) {
+		if site := c.site(); site != "" {
+			sites = append(sites, site)
+		}
+	}
+	return &planNode{stageInfo: info, callSite: strings.Join(sites, ", ")}
+}
+
+// Explain 按执行顺序逐行描述流水线的各个阶段：名称、标签、执行特性和创建位置
+func (s *streamImpl[T]) Explain() string {
+	var b strings.Builder
+	explainChain(&b, s.plan(), "", "")
+	return b.String()
+}
+
+func explainChain(b *strings.Builder, chain []*planNode, first, rest string) {
+	for i, node := range chain {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		b.WriteString(prefix)
+		b.WriteString(node.name)
+		if node.label != "" {
+			fmt.Fprintf(b, " %q", node.label)
+		}
+		if flags := node.flags(); len(flags) > 0 {
+			fmt.Fprintf(b, " [%s]", strings.Join(flags, ", "))
+		}
+		if node.callSite != "" {
+			b.WriteString(" @ ")
+			b.WriteString(node.callSite)
+		}
+		b.WriteByte('\n')
+		for j, input := range node.inputs {
+			if j == len(node.inputs)-1 {
+				explainChain(b, input, rest+"└─ ", rest+"   ")
+			} else {
+				explainChain(b, input, rest+"├─ ", rest+"│  ")
+			}
+		}
+	}
+}
+
+func (info stageInfo) flags() []string {
+	flags := make([]string, 0)
+	if info.barrier {
+		flags = append(flags, "barrier")
+	}
+	if info.shortCircuit {
+		flags = append(flags, "short-circuit")
+	}
+	if info.parallelism > 1 {
+		flags = append(flags, fmt.Sprintf("parallel=%d", info.parallelism))
+	}
+	return flags
+}
+
+// ToDOT 以 Graphviz DOT 格式输出流水线：需要收集全部元素的阶段以灰色填充，可能提前结束的阶段为八边形，并发阶段为双边框
+func (s *streamImpl[T]) ToDOT() string {
+	var b strings.Builder
+	b.WriteString("digraph stream {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	id := 0
+	dotChain(&b, s.plan(), &id)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotChain 输出一条链上的节点和边，返回最后一个节点的编号
+func dotChain(b *strings.Builder, chain []*planNode, id *int) int {
+	prev := -1
+	for _, node := range chain {
+		inputs := make([]int, len(node.inputs))
+		for i, input := range node.inputs {
+			inputs[i] = dotChain(b, input, id)
+		}
+
+		current := *id
+		*id++
+		lines := []string{node.name}
+		if node.label != "" {
+			lines = append(lines, fmt.Sprintf("%q", node.label))
+		}
+		if node.parallelism > 1 {
+			lines = append(lines, fmt.Sprintf("×%d", node.parallelism))
+		}
+		if node.callSite != "" {
+			lines = append(lines, node.callSite)
+		}
+		attrs := []string{fmt.Sprintf("label=\"%s\"", dotEscape(lines))}
+		if node.barrier {
+			attrs = append(attrs, "style=filled", "fillcolor=lightgrey")
+		}
+		if node.shortCircuit {
+			attrs = append(attrs, "shape=octagon")
+		}
+		if node.parallelism > 1 {
+			attrs = append(attrs, "peripheries=2")
+		}
+		fmt.Fprintf(b, "\tn%d [%s];\n", current, strings.Join(attrs, ", "))
+
+		for _, input := range inputs {
+			fmt.Fprintf(b, "\tn%d -> n%d;\n", input, current)
+		}
+		if prev >= 0 {
+			fmt.Fprintf(b, "\tn%d -> n%d;\n", prev, current)
+		}
+		prev = current
+	}
+	return prev
+}
+
+// dotEscape 转义 DOT 字符串中的反斜杠和引号，并以 \n 连接多行
+func dotEscape(lines []string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	for i, line := range lines {
+		lines[i] = replacer.Replace(line)
+	}
+	return strings.Join(lines, `\n`)
+}
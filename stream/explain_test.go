@@ -0,0 +1,96 @@
+package stream
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	SetCallSiteTracking(true)
+	defer SetCallSiteTracking(false)
+
+	s := Of(5, 3, 8, 1).Label("scores").
+		Filter(func(n int) bool { return n > 2 }).Label("passing").
+		Sorted(func(a, b int) int { return a - b }).
+		Limit(2)
+
+	lines := strings.Split(strings.TrimSuffix(s.Explain(), "\n"), "\n")
+	expected := []*regexp.Regexp{
+		regexp.MustCompile(`^Of "scores" @ explain_test\.go:\d+$`),
+		regexp.MustCompile(`^Filter "passing" @ explain_test\.go:\d+$`),
+		// 优化后合并的阶段列出每个原始阶段的创建位置
+		regexp.MustCompile(`^Sorted\+Limit \[barrier\] @ explain_test\.go:\d+, explain_test\.go:\d+$`),
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(expected), s.Explain())
+	}
+	for i, re := range expected {
+		if !re.MatchString(lines[i]) {
+			t.Errorf("Expected line %d to match %s, got %q", i, re, lines[i])
+		}
+	}
+
+	if result := s.ToSlice(); !equalSlices(result, []int{3, 5}) {
+		t.Errorf("Expected [3 5], got %v", result)
+	}
+}
+
+func TestExplainDerivedAndConcat(t *testing.T) {
+	SetCallSiteTracking(true)
+	defer SetCallSiteTracking(false)
+
+	words := RegexpMatches(regexp.MustCompile(`\w+`), "a b")
+	s := Concat(Of("x").Map(strings.ToUpper), Split("y,z", ",")).Skip(1)
+
+	if plan := words.Explain(); !strings.HasPrefix(plan, "RegexpSubmatches @ ") || !strings.Contains(plan, "\nRegexpMatches @ ") {
+		t.Errorf("Expected derived stream to include its upstream, got:\n%s", plan)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(s.Explain(), "\n"), "\n")
+	prefixes := []string{"Concat @ ", "├─ Of @ ", "│  Map @ ", "└─ Split @ ", "Skip @ "}
+	if len(lines) != len(prefixes) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(prefixes), s.Explain())
+	}
+	for i, prefix := range prefixes {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("Expected line %d to start with %q, got %q", i, prefix, lines[i])
+		}
+	}
+}
+
+func TestToDOT(t *testing.T) {
+	SetCallSiteTracking(true)
+	defer SetCallSiteTracking(false)
+
+	dot := Concat(Of(1), Of(2)).
+		Sorted(func(a, b int) int { return a - b }).Label(`by "value"`).
+		Limit(1).
+		ToDOT()
+
+	for _, want := range []string{
+		"digraph stream {",
+		`n2 [label="Concat\nexplain_test.go:`,
+		"n0 -> n2;",
+		"n1 -> n2;",
+		"n2 -> n3;",
+		`n3 [label="Sorted+Limit\n\"by \\\"value\\\"\"\nexplain_test.go:`,
+		"style=filled",
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected DOT output to contain %q, got:\n%s", want, dot)
+		}
+	}
+
+	dot = Concat(Of(1), Of(2)).Limit(1).ToDOT()
+	if !strings.Contains(dot, "shape=octagon") || !strings.Contains(dot, "n2 -> n3;") {
+		t.Errorf("Expected a short-circuit Limit node, got:\n%s", dot)
+	}
+}
+
+func TestExplainWithoutCallSites(t *testing.T) {
+	plan := Range(0, 10).Filter(func(n int64) bool { return n > 5 }).Label("tail").Explain()
+	if plan != "Range\nFilter \"tail\"\n" {
+		t.Errorf("Expected plan without call sites, got:\n%s", plan)
+	}
+}
@@ -1,55 +1,206 @@
 package stream
 
+import (
+	"errors"
+	"math"
+	"reflect"
+)
+
 func Of[T any](items ...T) Stream[T] {
-	return newStream(items)
+	return newStream("Of", items)
 }
 
 func OfSlice[T any](items []T) Stream[T] {
 	result := make([]T, len(items))
 	copy(result, items)
-	return newStream(result)
+	s := newStream("OfSlice", result)
+	s.traits |= Immutable
+	return s
 }
 
 func Range(startInclusive, endExclusive int64) Stream[int64] {
-	size := endExclusive - startInclusive
-	if size <= 0 {
-		return newStream([]int64{})
+	count, exact := rangeCount(startInclusive, endExclusive, 1, false)
+	return numericRange("Range", startInclusive, 1, count, exact)
+}
+
+func RangeClosed(startInclusive, endInclusive int64) Stream[int64] {
+	count, exact := rangeCount(startInclusive, endInclusive, 1, true)
+	return numericRange("RangeClosed", startInclusive, 1, count, exact)
+}
+
+// RangeStep 惰性地产生 start、start+step、start+2*step……中位于 end 之前（不包括 end）的值；
+// step 为负数时递减，为 0 时 panic
+func RangeStep[T Number](start, end, step T) Stream[T] {
+	if step == 0 {
+		panic("stream: RangeStep step must not be zero")
 	}
-	items := make([]int64, size)
-	for i := int64(0); i < size; i++ {
-		items[i] = startInclusive + i
+	count, exact := rangeCount(start, end, step, false)
+	return numericRange("RangeStep", start, step, count, exact)
+}
+
+// numericRange 创建第 i 个元素为 start+i*step 的区间流，元素按需计算，Skip、Limit 和 Count 无需遍历
+func numericRange[T Number](name string, start, step T, count int64, exact bool) Stream[T] {
+	s := newStream[T](name, nil)
+	s.size = count
+	if isFloat[T]() {
+		s.at = func(i int64) T {
+			return start + T(i)*step
+		}
+	} else {
+		// 整数在 uint64 上按补码运算，中间结果溢出不影响最终值
+		s.at = func(i int64) T {
+			return T(uint64(start) + uint64(i)*uint64(step))
+		}
+		s.traits |= Distinct
+	}
+	if !exact {
+		s.traits &^= Sized
+	}
+	s.traits |= Sorted | Immutable
+	s.sortedBy, s.ordering = NaturalOrder[T](), naturalOrdering{descending: step < 0}
+	if step < 0 {
+		s.sortedBy = reverseComparator(s.sortedBy)
 	}
-	return newStream(items)
+	return s
 }
 
-func RangeClosed(startInclusive, endInclusive int64) Stream[int64] {
-	size := endInclusive - startInclusive + 1
-	if size <= 0 {
-		return newStream([]int64{})
+// rangeCount 计算区间中的元素个数，整数使用无符号运算以避免溢出；个数超过 math.MaxInt64 时截断并返回 false
+func rangeCount[T Number](start, end, step T, closed bool) (int64, bool) {
+	if step > 0 && (end < start || end == start && !closed) || step < 0 && (end > start || end == start && !closed) {
+		return 0, true
+	}
+
+	if isFloat[T]() {
+		n := float64(end-start) / float64(step)
+		count := math.Ceil(n)
+		if closed {
+			count = math.Floor(n) + 1
+		}
+		switch {
+		case math.IsNaN(count):
+			return 0, true
+		case count >= math.MaxInt64:
+			return math.MaxInt64, false
+		}
+		return adjustFloatCount(start, end, step, closed, int64(count)), true
 	}
-	items := make([]int64, size)
-	for i := int64(0); i < size; i++ {
-		items[i] = startInclusive + i
+
+	distance, stride := uint64(end)-uint64(start), uint64(step)
+	if step < 0 {
+		distance, stride = uint64(start)-uint64(end), -uint64(step)
+	}
+	var quotient uint64
+	if closed {
+		quotient = distance / stride
+	} else {
+		quotient = (distance - 1) / stride
 	}
-	return newStream(items)
+	if quotient >= math.MaxInt64 {
+		return math.MaxInt64, false
+	}
+	return int64(quotient) + 1, true
+}
+
+// adjustFloatCount 修正由舍入后的商得到的个数，使最后一个元素 start+(count-1)*step 按与 at 相同的方式计算后
+// 仍然位于区间内，而下一个元素位于区间外；精度不足以区分相邻元素时不做修正
+func adjustFloatCount[T Number](start, end, step T, closed bool, count int64) int64 {
+	if count >= 1<<53 {
+		return count
+	}
+	beyond := func(i int64) bool {
+		v := start + T(i)*step
+		switch {
+		case step > 0 && closed:
+			return v > end
+		case step > 0:
+			return v >= end
+		case closed:
+			return v < end
+		default:
+			return v <= end
+		}
+	}
+	for count > 0 && beyond(count-1) {
+		count--
+	}
+	for count < 1<<53 && !beyond(count) {
+		count++
+	}
+	return count
+}
+
+func isFloat[T Number]() bool {
+	switch reflect.TypeOf((*T)(nil)).Elem().Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
 }
 
 func Empty[T any]() Stream[T] {
-	return newStream([]T{})
+	s := newStream("Empty", []T{})
+	s.traits |= Distinct | Immutable
+	return s
 }
 
 func Generate[T any](supplier Supplier[T], count int) Stream[T] {
-	items := make([]T, count)
-	for i := 0; i < count; i++ {
-		items[i] = supplier()
-	}
-	return newStream(items)
+	s := newLazyStream("Generate", func(s *streamImpl[T]) iterator[T] {
+		generated := 0
+		return func() (T, bool) {
+			if generated >= count {
+				var zero T
+				return zero, false
+			}
+			generated++
+			return supplier(), true
+		}
+	})
+	s.traits |= Sized
+	s.size = int64(max(count, 0))
+	return s
 }
 
+// Concat 按顺序惰性地连接多个流，每个流耗尽后立即关闭
 func Concat[T any](streams ...Stream[T]) Stream[T] {
-	var allItems []T
-	for _, s := range streams {
-		allItems = append(allItems, s.ToSlice()...)
+	s := newLazyStream("Concat", func(s *streamImpl[T]) iterator[T] {
+		s.onClose(func() error {
+			errs := make([]error, 0)
+			for _, st := range streams {
+				st.close()
+				errs = append(errs, st.Err())
+			}
+			return errors.Join(errs...)
+		})
+		i := 0
+		var current iterator[T]
+		return func() (T, bool) {
+			for i < len(streams) {
+				if current == nil {
+					current = streams[i].iterate()
+				}
+				if item, ok := current(); ok {
+					return item, true
+				}
+				streams[i].close()
+				current = nil
+				i++
+			}
+			var zero T
+			return zero, false
+		}
+	})
+	// 所有输入的元素个数都确切已知时，连接后的元素个数也确切已知
+	s.traits |= Sized
+	s.size = 0
+	for _, st := range streams {
+		s.inputs = append(s.inputs, st)
+		c, size := st.Characteristics(), st.EstimateSize()
+		if !c.Has(Sized) {
+			s.traits &^= Sized
+			s.size = -1
+		} else if s.traits&Sized != 0 {
+			s.size += size
+		}
 	}
-	return newStream(allItems)
+	return s
 }
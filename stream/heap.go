@@ -0,0 +1,99 @@
+package stream
+
+// boundedHeap 是容量受限的二叉堆，堆顶为比较器意义下的最小元素
+type boundedHeap[T any] struct {
+	items      []T
+	capacity   int
+	comparator Comparator[T]
+}
+
+func newBoundedHeap[T any](capacity int, comparator Comparator[T]) *boundedHeap[T] {
+	return &boundedHeap[T]{
+		items:      make([]T, 0, min(capacity, 1024)),
+		capacity:   capacity,
+		comparator: comparator,
+	}
+}
+
+// offer 尝试放入元素，堆满时只有比堆顶更大的元素才会替换堆顶
+func (h *boundedHeap[T]) offer(item T) {
+	if h.capacity <= 0 {
+		return
+	}
+	if len(h.items) < h.capacity {
+		h.items = append(h.items, item)
+		h.up(len(h.items) - 1)
+		return
+	}
+	if h.comparator(item, h.items[0]) > 0 {
+		h.items[0] = item
+		h.down(0)
+	}
+}
+
+func (h *boundedHeap[T]) pop() T {
+	n := len(h.items) - 1
+	top := h.items[0]
+	h.items[0] = h.items[n]
+	h.items = h.items[:n]
+	if n > 0 {
+		h.down(0)
+	}
+	return top
+}
+
+// drainDescending 清空堆并按从大到小的顺序返回元素
+func (h *boundedHeap[T]) drainDescending() []T {
+	result := make([]T, len(h.items))
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = h.pop()
+	}
+	return result
+}
+
+func (h *boundedHeap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if h.comparator(h.items[i], h.items[parent]) >= 0 {
+			break
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+func (h *boundedHeap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		smallest := i
+		left, right := 2*i+1, 2*i+2
+		if left < n && h.comparator(h.items[left], h.items[smallest]) < 0 {
+			smallest = left
+		}
+		if right < n && h.comparator(h.items[right], h.items[smallest]) < 0 {
+			smallest = right
+		}
+		if smallest == i {
+			return
+		}
+		h.items[i], h.items[smallest] = h.items[smallest], h.items[i]
+		i = smallest
+	}
+}
+
+func reverseComparator[T any](comparator Comparator[T]) Comparator[T] {
+	return func(a, b T) int {
+		return comparator(b, a)
+	}
+}
+
+func topK[T any](it iterator[T], k int, comparator Comparator[T]) []T {
+	if k <= 0 {
+		return []T{}
+	}
+	h := newBoundedHeap(k, comparator)
+	for item, ok := it(); ok; item, ok = it() {
+		h.offer(item)
+	}
+	return h.drainDescending()
+}
@@ -0,0 +1,86 @@
+package stream
+
+import (
+	"testing"
+)
+
+func TestMaxMin(t *testing.T) {
+	max := Of(3, 9, 1, 7).Max(NaturalOrder[int]())
+	if !max.IsPresent() || max.Get() != 9 {
+		t.Errorf("Expected 9, got %v", max.Get())
+	}
+
+	min := Of(3, 9, 1, 7).Min(NaturalOrder[int]())
+	if !min.IsPresent() || min.Get() != 1 {
+		t.Errorf("Expected 1, got %v", min.Get())
+	}
+
+	if Empty[int]().Max(NaturalOrder[int]()).IsPresent() {
+		t.Errorf("Expected empty, got present")
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	smallest, largest := Of(4, 2, 8, 6).MinMax(NaturalOrder[int]())
+	if smallest.Get() != 2 || largest.Get() != 8 {
+		t.Errorf("Expected (2, 8), got (%d, %d)", smallest.Get(), largest.Get())
+	}
+
+	smallest, largest = Empty[int]().MinMax(NaturalOrder[int]())
+	if smallest.IsPresent() || largest.IsPresent() {
+		t.Errorf("Expected empty results for empty stream")
+	}
+}
+
+func TestMaxBy(t *testing.T) {
+	type request struct {
+		path     string
+		duration int
+	}
+	requests := []request{{"/a", 30}, {"/b", 120}, {"/c", 45}, {"/d", 120}}
+
+	slowest := MaxBy(OfSlice(requests), func(r request) int { return r.duration })
+	if slowest.Get().path != "/b" {
+		t.Errorf("Expected /b, got %s", slowest.Get().path)
+	}
+
+	fastest := MinBy(OfSlice(requests), func(r request) int { return r.duration })
+	if fastest.Get().path != "/a" {
+		t.Errorf("Expected /a, got %s", fastest.Get().path)
+	}
+}
+
+func TestTopK(t *testing.T) {
+	result := Of(5, 1, 9, 3, 7, 2, 8).TopK(3, NaturalOrder[int]())
+
+	expected := []int{9, 8, 7}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected length %d, got %d", len(expected), len(result))
+	}
+	for i, v := range result {
+		if v != expected[i] {
+			t.Errorf("Expected %d at index %d, got %d", expected[i], i, v)
+		}
+	}
+
+	if len(Of(1, 2).TopK(5, NaturalOrder[int]())) != 2 {
+		t.Errorf("Expected TopK larger than stream to return all elements")
+	}
+	if len(Of(1, 2).TopK(0, NaturalOrder[int]())) != 0 {
+		t.Errorf("Expected TopK(0) to return no elements")
+	}
+}
+
+func TestBottomK(t *testing.T) {
+	result := Of(5, 1, 9, 3, 7, 2, 8).BottomK(3, NaturalOrder[int]())
+
+	expected := []int{1, 2, 3}
+	if len(result) != len(expected) {
+		t.Fatalf("Expected length %d, got %d", len(expected), len(result))
+	}
+	for i, v := range result {
+		if v != expected[i] {
+			t.Errorf("Expected %d at index %d, got %d", expected[i], i, v)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package stream
+
+// iterator 按需拉取下一个元素，第二个返回值为 false 表示已经没有更多元素
+type iterator[T any] func() (T, bool)
+
+// stage 是流水线中的一个中间操作
+type stage[T any] struct {
+	stageInfo
+	apply func(iterator[T]) iterator[T]
+
+	// 以下字段供优化器改写 Filter、Map、Sorted、Limit 和 Skip
+	predicate  Predicate[T]
+	mapper     Function[T, T]
+	comparator Comparator[T]
+	n          int64
+	// ordering 标识 Sorted 的排序依据，非 nil 且相等时排序依据相同，见 naturalOrdering
+	ordering any
+}
+
+func sliceIterator[T any](items []T) iterator[T] {
+	i := 0
+	return func() (T, bool) {
+		if i >= len(items) {
+			var zero T
+			return zero, false
+		}
+		item := items[i]
+		i++
+		return item, true
+	}
+}
+
+// indexIterator 依次产生 at(from) 到 at(to-1)
+func indexIterator[T any](at func(int64) T, from, to int64) iterator[T] {
+	i := from
+	return func() (T, bool) {
+		if i >= to {
+			var zero T
+			return zero, false
+		}
+		// 先前进再取值，at 发生 panic 并被跳过时不会重复访问同一个下标
+		i++
+		return at(i - 1), true
+	}
+}
+
+func emptyIterator[T any]() iterator[T] {
+	return func() (T, bool) {
+		var zero T
+		return zero, false
+	}
+}
+
+// drain 拉取全部元素，capacity 为预先分配的容量
+func drain[T any](it iterator[T], capacity int) []T {
+	result := make([]T, 0, capacity)
+	for item, ok := it(); ok; item, ok = it() {
+		result = append(result, item)
+	}
+	return result
+}
+
+// barrier 将需要看到全部元素的批量操作（如排序）包装为迭代器操作，首次拉取时才收集上游
+func barrier[T any](op func([]T) []T) func(iterator[T]) iterator[T] {
+	return gather(func(upstream iterator[T]) []T {
+		return op(drain(upstream, 0))
+	})
+}
+
+// gather 与 barrier 相同，但由 op 自己拉取上游，只需要保留部分元素（如抽样）时不必收集全部元素
+func gather[T any](op func(iterator[T]) []T) func(iterator[T]) iterator[T] {
+	return func(upstream iterator[T]) iterator[T] {
+		var result iterator[T]
+		return func() (T, bool) {
+			if result == nil {
+				result = sliceIterator(op(upstream))
+			}
+			return result()
+		}
+	}
+}
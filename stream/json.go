@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// FromJSONLines 惰性地解码以换行分隔的 JSON 值（NDJSON），空行会被忽略
+func FromJSONLines[T any](r io.Reader) Stream[T] {
+	return newLazyStream("FromJSONLines", func(s *streamImpl[T]) iterator[T] {
+		decoder := json.NewDecoder(r)
+		records := 0
+		return func() (T, bool) {
+			var item T
+			if err := decoder.Decode(&item); err != nil {
+				if !errors.Is(err, io.EOF) {
+					s.fail(fmt.Errorf("json lines: record %d: %w", records+1, err))
+				}
+				return item, false
+			}
+			records++
+			return item, true
+		}
+	})
+}
+
+// FromJSONArray 惰性地逐个解码顶层 JSON 数组中的元素，不会把整个文档载入内存
+func FromJSONArray[T any](r io.Reader) Stream[T] {
+	return newLazyStream("FromJSONArray", func(s *streamImpl[T]) iterator[T] {
+		decoder := json.NewDecoder(r)
+		if err := expectDelim(decoder, '['); err != nil {
+			s.fail(err)
+			return emptyIterator[T]()
+		}
+		done := false
+		elements := 0
+		return func() (T, bool) {
+			var item T
+			if done {
+				return item, false
+			}
+			if !decoder.More() {
+				done = true
+				if err := expectDelim(decoder, ']'); err != nil {
+					s.fail(err)
+				}
+				return item, false
+			}
+			if err := decoder.Decode(&item); err != nil {
+				done = true
+				s.fail(fmt.Errorf("json array: element %d: %w", elements, err))
+				return item, false
+			}
+			elements++
+			return item, true
+		}
+	})
+}
+
+func expectDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("json array: %w", err)
+	}
+	if token != delim {
+		return fmt.Errorf("json array: expected %q, got %v", delim, token)
+	}
+	return nil
+}
+
+// WriteJSONLines 将每个元素编码为一行 JSON 写入 w
+func WriteJSONLines[T any](s Stream[T], w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+	encoder.SetEscapeHTML(false)
+
+	it := s.iterate()
+	defer s.close()
+	for item, ok := it(); ok; item, ok = it() {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	return s.Err()
+}
+
+// WriteJSONArray 将元素以 JSON 数组的形式逐个写入 w，空流写出 []
+func WriteJSONArray[T any](s Stream[T], w io.Writer) error {
+	buffered := bufio.NewWriter(w)
+	if err := buffered.WriteByte('['); err != nil {
+		return err
+	}
+	// 与 WriteJSONLines 一样不转义 HTML 字符；Encoder 在每个值后追加的换行需要去掉
+	var element bytes.Buffer
+	encoder := json.NewEncoder(&element)
+	encoder.SetEscapeHTML(false)
+
+	it := s.iterate()
+	defer s.close()
+	first := true
+	for item, ok := it(); ok; item, ok = it() {
+		element.Reset()
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		if !first {
+			buffered.WriteByte(',')
+		}
+		first = false
+		if _, err := buffered.Write(bytes.TrimSuffix(element.Bytes(), []byte{'\n'})); err != nil {
+			return err
+		}
+	}
+	if err := buffered.WriteByte(']'); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	return s.Err()
+}
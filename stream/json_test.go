@@ -0,0 +1,97 @@
+package stream
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type jsonEvent struct {
+	User   string `json:"user"`
+	Action string `json:"action"`
+}
+
+func TestFromJSONLines(t *testing.T) {
+	input := `{"user":"alice","action":"login"}
+{"user":"bob","action":"logout"}
+
+{"user":"alice","action":"logout"}
+`
+	result := FromJSONLines[jsonEvent](strings.NewReader(input)).
+		Filter(func(e jsonEvent) bool { return e.User == "alice" }).
+		ToSlice()
+
+	if len(result) != 2 || result[1].Action != "logout" {
+		t.Errorf("Unexpected events: %+v", result)
+	}
+}
+
+func TestFromJSONLinesError(t *testing.T) {
+	s := FromJSONLines[jsonEvent](strings.NewReader("{\"user\":\"alice\"}\n{broken\n"))
+	if s.Count() != 1 {
+		t.Errorf("Expected stream to stop at invalid record")
+	}
+	if s.Err() == nil || !strings.Contains(s.Err().Error(), "record 2") {
+		t.Errorf("Expected error for record 2, got %v", s.Err())
+	}
+}
+
+func TestFromJSONArray(t *testing.T) {
+	first := FromJSONArray[int](strings.NewReader("[1, 2, 3, 4]")).
+		Filter(func(n int) bool { return n > 1 }).
+		FindFirst()
+	if first.Get() != 2 {
+		t.Errorf("Expected 2, got %d", first.Get())
+	}
+
+	count := FromJSONArray[int](strings.NewReader("[]")).Count()
+	if count != 0 {
+		t.Errorf("Expected 0, got %d", count)
+	}
+
+	s := FromJSONArray[int](strings.NewReader(`{"not":"array"}`))
+	if s.Count() != 0 || s.Err() == nil {
+		t.Errorf("Expected error for non-array input")
+	}
+}
+
+func TestWriteJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteJSONLines(Of(jsonEvent{"a", "x"}, jsonEvent{"b", "<y>"}), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\"user\":\"a\",\"action\":\"x\"}\n{\"user\":\"b\",\"action\":\"<y>\"}\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestWriteJSONArray(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSONArray(Range(1, 4), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[1,2,3]" {
+		t.Errorf("Expected [1,2,3], got %s", buf.String())
+	}
+
+	var decoded []int64
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || len(decoded) != 3 {
+		t.Errorf("Expected valid JSON array, got %v (%v)", decoded, err)
+	}
+
+	buf.Reset()
+	WriteJSONArray(Empty[int](), &buf)
+	if buf.String() != "[]" {
+		t.Errorf("Expected [], got %s", buf.String())
+	}
+
+	buf.Reset()
+	WriteJSONArray(Of(jsonEvent{"b", "<y>"}), &buf)
+	if expected := `[{"user":"b","action":"<y>"}]`; buf.String() != expected {
+		t.Errorf("Expected %s, got %s", expected, buf.String())
+	}
+}
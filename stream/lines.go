@@ -0,0 +1,127 @@
+package stream
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// DefaultMaxLineLength 是行数据源默认允许的最大行长度（字节）
+const DefaultMaxLineLength = 1024 * 1024
+
+var ErrLineTooLong = errors.New("stream: line too long")
+
+type LineOption func(*lineConfig)
+
+type lineConfig struct {
+	maxLineLength int
+	decompress    bool
+}
+
+// WithMaxLineLength 设置单行（或单个 token）的最大字节数，不含行尾，超出时流停止并通过 Err 返回 ErrLineTooLong
+func WithMaxLineLength(n int) LineOption {
+	return func(c *lineConfig) {
+		c.maxLineLength = n
+	}
+}
+
+// WithoutDecompression 关闭 gzip 自动识别，按原始字节读取
+func WithoutDecompression() LineOption {
+	return func(c *lineConfig) {
+		c.decompress = false
+	}
+}
+
+func newLineConfig(opts []LineOption) lineConfig {
+	cfg := lineConfig{
+		maxLineLength: DefaultMaxLineLength,
+		decompress:    true,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Lines 惰性地逐行读取 r，行尾的 "\n" 和 "\r\n" 会被去掉；r 由调用方负责关闭
+func Lines(r io.Reader, opts ...LineOption) Stream[string] {
+	return newLazyStream("Lines", func(s *streamImpl[string]) iterator[string] {
+		return scanTokens(s, r, bufio.ScanLines, "line", newLineConfig(opts))
+	})
+}
+
+// LinesFromFile 在终端操作开始时打开文件，并在终端操作结束（包括短路提前结束）时关闭
+func LinesFromFile(path string, opts ...LineOption) Stream[string] {
+	return newLazyStream("LinesFromFile", func(s *streamImpl[string]) iterator[string] {
+		f, err := os.Open(path)
+		if err != nil {
+			s.fail(err)
+			return emptyIterator[string]()
+		}
+		s.onClose(f.Close)
+		return scanTokens(s, f, bufio.ScanLines, "line", newLineConfig(opts))
+	})
+}
+
+// Scan 使用任意 bufio.SplitFunc 切分 r，例如 bufio.ScanWords、bufio.ScanRunes
+func Scan(r io.Reader, split bufio.SplitFunc, opts ...LineOption) Stream[string] {
+	return newLazyStream("Scan", func(s *streamImpl[string]) iterator[string] {
+		return scanTokens(s, r, split, "token", newLineConfig(opts))
+	})
+}
+
+func scanTokens(s *streamImpl[string], r io.Reader, split bufio.SplitFunc, unit string, cfg lineConfig) iterator[string] {
+	if cfg.decompress {
+		reader, err := maybeGunzip(r)
+		if err != nil {
+			s.fail(err)
+			return emptyIterator[string]()
+		}
+		if zr, ok := reader.(*gzip.Reader); ok {
+			// 关闭时报告解压过程中的错误，不会关闭底层的 r
+			s.onClose(zr.Close)
+		}
+		r = reader
+	}
+
+	// 缓冲区额外留出行尾 "\r\n" 的空间，长度限制只针对去掉行尾之后的内容
+	limit := max(cfg.maxLineLength, 0)
+	bufferSize := min(limit, math.MaxInt-2) + 2
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(bufferSize, bufio.MaxScanTokenSize)), bufferSize)
+	scanner.Split(split)
+
+	tokens := 0
+	tooLong := func() (string, bool) {
+		s.fail(fmt.Errorf("%w: %s %d exceeds %d bytes", ErrLineTooLong, unit, tokens+1, limit))
+		return "", false
+	}
+	return func() (string, bool) {
+		if !scanner.Scan() {
+			if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+				return tooLong()
+			}
+			s.fail(scanner.Err())
+			return "", false
+		}
+		if len(scanner.Bytes()) > limit {
+			return tooLong()
+		}
+		tokens++
+		return scanner.Text(), true
+	}
+}
+
+// maybeGunzip 通过 gzip 魔数识别压缩数据，非压缩数据原样返回
+func maybeGunzip(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
@@ -0,0 +1,114 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	input := "INFO start\nERROR disk full\r\nINFO done\nERROR timeout"
+	result := Lines(strings.NewReader(input)).
+		Filter(func(line string) bool { return strings.HasPrefix(line, "ERROR") }).
+		ToSlice()
+
+	expected := []string{"ERROR disk full", "ERROR timeout"}
+	if !equalSlices(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestLinesGzip(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte("a\nb\nc\n"))
+	w.Close()
+
+	result := Lines(&buf).ToSlice()
+	if !equalSlices(result, []string{"a", "b", "c"}) {
+		t.Errorf("Expected [a b c], got %v", result)
+	}
+}
+
+func TestLinesMaxLineLength(t *testing.T) {
+	s := Lines(strings.NewReader("short\n"+strings.Repeat("x", 100)+"\nnever"), WithMaxLineLength(16))
+	result := s.ToSlice()
+
+	if !equalSlices(result, []string{"short"}) {
+		t.Errorf("Expected [short], got %v", result)
+	}
+	if !errors.Is(s.Err(), ErrLineTooLong) {
+		t.Errorf("Expected ErrLineTooLong, got %v", s.Err())
+	}
+}
+
+func TestLinesMaxLineLengthBoundary(t *testing.T) {
+	result := Lines(strings.NewReader("abcde\nxy\r\nvwxyz\r\n"), WithMaxLineLength(5)).ToSlice()
+	if !equalSlices(result, []string{"abcde", "xy", "vwxyz"}) {
+		t.Errorf("Expected lines of exactly 5 bytes to be accepted, got %v", result)
+	}
+
+	s := Lines(strings.NewReader("abcde\nabcdef\n"), WithMaxLineLength(5))
+	if result := s.ToSlice(); !equalSlices(result, []string{"abcde"}) {
+		t.Errorf("Expected [abcde], got %v", result)
+	}
+	if !errors.Is(s.Err(), ErrLineTooLong) || !strings.Contains(s.Err().Error(), "line 2 exceeds 5 bytes") {
+		t.Errorf("Expected line 2 to be too long, got %v", s.Err())
+	}
+}
+
+func TestScanWords(t *testing.T) {
+	count := Scan(strings.NewReader("the quick  brown\nfox"), bufio.ScanWords).Count()
+	if count != 4 {
+		t.Errorf("Expected 4, got %d", count)
+	}
+}
+
+func TestLinesFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("one\ntwo\nthree\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	first := LinesFromFile(path).FindFirst()
+	if first.Get() != "one" {
+		t.Errorf("Expected one, got %s", first.Get())
+	}
+
+	s := LinesFromFile(filepath.Join(t.TempDir(), "missing.log"))
+	if s.Count() != 0 {
+		t.Errorf("Expected empty stream for missing file")
+	}
+	if !errors.Is(s.Err(), os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", s.Err())
+	}
+}
+
+func TestLazySourceClosedOnEarlyExit(t *testing.T) {
+	pulled, closed := 0, false
+	s := newLazyStream("test", func(s *streamImpl[int]) iterator[int] {
+		s.onClose(func() error {
+			closed = true
+			return nil
+		})
+		return func() (int, bool) {
+			pulled++
+			return pulled, true
+		}
+	})
+
+	if !s.AnyMatch(func(n int) bool { return n == 3 }) {
+		t.Errorf("Expected match")
+	}
+	if pulled != 3 {
+		t.Errorf("Expected 3 elements pulled, got %d", pulled)
+	}
+	if !closed {
+		t.Errorf("Expected source to be closed after short-circuit")
+	}
+}
@@ -0,0 +1,85 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type LogOption func(*logConfig)
+
+type logConfig struct {
+	every int64
+}
+
+// LogEvery 只记录每 n 个元素中的第一个，用于降低高吞吐流水线的日志量
+func LogEvery(n int64) LogOption {
+	return func(c *logConfig) {
+		c.every = n
+	}
+}
+
+// Log 在元素经过时写一条结构化日志；attrs 为 nil 时以 element 属性记录元素本身
+func (s *streamImpl[T]) Log(logger *slog.Logger, level slog.Level, msg string, attrs func(T) []slog.Attr, opts ...LogOption) Stream[T] {
+	cfg := logConfig{every: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.every < 1 {
+		cfg.every = 1
+	}
+
+	return s.pipe("Log", func(upstream iterator[T]) iterator[T] {
+		ctx := context.Background()
+		var index int64
+		return func() (T, bool) {
+			item, ok := upstream()
+			if !ok {
+				return item, false
+			}
+			if index%cfg.every == 0 && logger.Enabled(ctx, level) {
+				record := []slog.Attr{slog.Int64("index", index)}
+				if attrs != nil {
+					record = append(record, attrs(item)...)
+				} else {
+					record = append(record, slog.Any("element", item))
+				}
+				logger.LogAttrs(ctx, level, msg, record...)
+			}
+			index++
+			return item, true
+		}
+	})
+}
+
+type summaryObserver struct {
+	logger *slog.Logger
+	level  slog.Level
+	msg    string
+}
+
+// LogSummary 在终端操作结束时写一条日志，记录数据源产生的元素数以及每个中间操作的输入、输出元素数
+func (s *streamImpl[T]) LogSummary(logger *slog.Logger, level slog.Level, msg string) Stream[T] {
+	return s.Observe(&summaryObserver{logger: logger, level: level, msg: msg})
+}
+
+func (o *summaryObserver) OnStage(StageStats) {}
+
+func (o *summaryObserver) OnPipeline(stats PipelineStats) {
+	attrs := make([]slog.Attr, 0, len(stats.Stages)+2)
+	for _, st := range stats.Stages {
+		if st.Index == 0 {
+			attrs = append(attrs, slog.Int64("source", st.Out))
+			continue
+		}
+		attrs = append(attrs, slog.Group(fmt.Sprintf("%d.%s", st.Index, st.Name),
+			slog.Int64("in", st.In),
+			slog.Int64("out", st.Out),
+		))
+	}
+	attrs = append(attrs, slog.Duration("elapsed", stats.Elapsed))
+	if stats.Err != nil {
+		attrs = append(attrs, slog.Any("error", stats.Err))
+	}
+	o.logger.LogAttrs(context.Background(), o.level, o.msg, attrs...)
+}
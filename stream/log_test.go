@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func newTestLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "elapsed" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+func TestLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf)
+
+	result := Of(1, 2, 3).
+		Log(logger, slog.LevelDebug, "before filter", nil).
+		Filter(func(n int) bool { return n != 2 }).
+		Log(logger, slog.LevelInfo, "kept", func(n int) []slog.Attr {
+			return []slog.Attr{slog.Int("value", n)}
+		}).
+		ToSlice()
+
+	if !equalSlices(result, []int{1, 3}) {
+		t.Errorf("Expected [1 3], got %v", result)
+	}
+	expected := "" +
+		"level=DEBUG msg=\"before filter\" index=0 element=1\n" +
+		"level=INFO msg=kept index=0 value=1\n" +
+		"level=DEBUG msg=\"before filter\" index=1 element=2\n" +
+		"level=DEBUG msg=\"before filter\" index=2 element=3\n" +
+		"level=INFO msg=kept index=1 value=3\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestLogEvery(t *testing.T) {
+	var buf bytes.Buffer
+	Range(0, 100).Log(newTestLogger(&buf), slog.LevelInfo, "tick", nil, LogEvery(25)).Count()
+
+	if lines := strings.Count(buf.String(), "\n"); lines != 4 {
+		t.Errorf("Expected 4 log lines, got %d:\n%s", lines, buf.String())
+	}
+	if !strings.Contains(buf.String(), "index=75 element=75") {
+		t.Errorf("Expected every 25th element to be logged, got:\n%s", buf.String())
+	}
+}
+
+func TestLogSummary(t *testing.T) {
+	var buf bytes.Buffer
+	count := Range(0, 10).
+		LogSummary(newTestLogger(&buf), slog.LevelInfo, "pipeline done").
+		Filter(func(n int64) bool { return n%2 == 0 }).
+		Map(func(n int64) int64 { return n * 10 }).
+		Limit(3).
+		Count()
+
+	if count != 3 {
+		t.Errorf("Expected 3, got %d", count)
+	}
+	expected := "level=INFO msg=\"pipeline done\" source=5 1.Filter+Map.in=5 1.Filter+Map.out=3 2.Limit.in=3 2.Limit.out=3\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
@@ -0,0 +1,191 @@
+package stream
+
+import (
+	"sort"
+)
+
+// NumberStream 是元素为数值的流，中间操作仍然返回 NumberStream，并提供求和、统计等数值终端操作；
+// 其余操作与 Stream 相同，需要 Stream[N] 时使用 Boxed
+type NumberStream[N Number] struct {
+	Stream[N]
+}
+
+// Statistics 是单次遍历得到的汇总统计，Average 按 float64 累加计算，没有元素时各字段均为零值
+type Statistics[N Number] struct {
+	Count   int64
+	Sum     N
+	Min     N
+	Max     N
+	Average float64
+}
+
+// Numbers 把元素为数值的流转换为 NumberStream，例如 Numbers(Range(0, 10))
+func Numbers[N Number](s Stream[N]) NumberStream[N] {
+	return NumberStream[N]{Stream: s}
+}
+
+func OfNumbers[N Number](items ...N) NumberStream[N] {
+	return Numbers(newStream("OfNumbers", items))
+}
+
+// MapToNumber 把每个元素映射为数值
+func MapToNumber[T any, N Number](s Stream[T], mapper Function[T, N]) NumberStream[N] {
+	return Numbers[N](derive("MapToNumber", s, func(_ *streamImpl[N], upstream iterator[T]) iterator[N] {
+		return func() (N, bool) {
+			item, ok := upstream()
+			if !ok {
+				return 0, false
+			}
+			return mapper(item), true
+		}
+	}))
+}
+
+// Boxed 返回底层的 Stream[N]
+func (s NumberStream[N]) Boxed() Stream[N] {
+	return s.Stream
+}
+
+// AsFloat 把每个元素转换为 float64
+func (s NumberStream[N]) AsFloat() NumberStream[float64] {
+	return MapToNumber(s.Stream, func(n N) float64 {
+		return float64(n)
+	})
+}
+
+func (s NumberStream[N]) Filter(predicate Predicate[N]) NumberStream[N] {
+	return Numbers(s.Stream.Filter(predicate))
+}
+
+func (s NumberStream[N]) Map(mapper Function[N, N]) NumberStream[N] {
+	return Numbers(s.Stream.Map(mapper))
+}
+
+func (s NumberStream[N]) Distinct() NumberStream[N] {
+	return Numbers(s.Stream.Distinct())
+}
+
+func (s NumberStream[N]) Sorted(comparator Comparator[N]) NumberStream[N] {
+	return Numbers(s.Stream.Sorted(comparator))
+}
+
+func (s NumberStream[N]) Limit(maxSize int64) NumberStream[N] {
+	return Numbers(s.Stream.Limit(maxSize))
+}
+
+func (s NumberStream[N]) Skip(n int64) NumberStream[N] {
+	return Numbers(s.Stream.Skip(n))
+}
+
+func (s NumberStream[N]) Peek(consumer Consumer[N]) NumberStream[N] {
+	return Numbers(s.Stream.Peek(consumer))
+}
+
+// CumulativeSum 惰性地产生前缀和：第 i 个元素为原来前 i+1 个元素之和
+func (s NumberStream[N]) CumulativeSum() NumberStream[N] {
+	return Numbers(s.Stream.pipe("CumulativeSum", func(upstream iterator[N]) iterator[N] {
+		var sum N
+		return func() (N, bool) {
+			item, ok := upstream()
+			if !ok {
+				return 0, false
+			}
+			sum += item
+			return sum, true
+		}
+	}))
+}
+
+func (s NumberStream[N]) Sum() N {
+	it := s.iterate()
+	defer s.close()
+	var sum N
+	for item, ok := it(); ok; item, ok = it() {
+		sum += item
+	}
+	return sum
+}
+
+// Average 返回算术平均值，没有元素时返回空的 Optional
+func (s NumberStream[N]) Average() Optional[float64] {
+	stats := s.Statistics()
+	if stats.Count == 0 {
+		return EmptyOptional[float64]()
+	}
+	return OfOptional(stats.Average)
+}
+
+// Min 返回最小值；浮点数中存在 NaN 时返回 NaN
+func (s NumberStream[N]) Min() Optional[N] {
+	return s.extreme(func(a, b N) N { return min(a, b) })
+}
+
+// Max 返回最大值；浮点数中存在 NaN 时返回 NaN
+func (s NumberStream[N]) Max() Optional[N] {
+	return s.extreme(func(a, b N) N { return max(a, b) })
+}
+
+func (s NumberStream[N]) extreme(pick func(a, b N) N) Optional[N] {
+	it := s.iterate()
+	defer s.close()
+	result, ok := it()
+	if !ok {
+		return EmptyOptional[N]()
+	}
+	for item, ok := it(); ok; item, ok = it() {
+		result = pick(result, item)
+	}
+	return OfOptional(result)
+}
+
+func (s NumberStream[N]) Statistics() Statistics[N] {
+	it := s.iterate()
+	defer s.close()
+	var stats Statistics[N]
+	var total float64
+	for item, ok := it(); ok; item, ok = it() {
+		if stats.Count == 0 {
+			stats.Min, stats.Max = item, item
+		} else {
+			stats.Min, stats.Max = min(stats.Min, item), max(stats.Max, item)
+		}
+		stats.Count++
+		stats.Sum += item
+		total += float64(item)
+	}
+	if stats.Count > 0 {
+		stats.Average = total / float64(stats.Count)
+	}
+	return stats
+}
+
+// Histogram 按升序的边界统计每个区间中的元素个数，返回 len(bounds)+1 个计数：
+// 第 0 个为小于 bounds[0] 的元素，第 i 个为位于 [bounds[i-1], bounds[i]) 的元素，最后一个为不小于最后一个边界的元素
+func (s NumberStream[N]) Histogram(bounds ...N) []int64 {
+	counts := make([]int64, len(bounds)+1)
+	it := s.iterate()
+	defer s.close()
+	for item, ok := it(); ok; item, ok = it() {
+		counts[sort.Search(len(bounds), func(i int) bool { return item < bounds[i] })]++
+	}
+	return counts
+}
+
+// Dot 返回两个流逐个对应元素乘积之和，较长的流多出的元素被忽略
+func (s NumberStream[N]) Dot(other NumberStream[N]) N {
+	left, right := s.iterate(), other.iterate()
+	defer s.close()
+	defer other.close()
+	var sum N
+	for {
+		a, ok := left()
+		if !ok {
+			return sum
+		}
+		b, ok := right()
+		if !ok {
+			return sum
+		}
+		sum += a * b
+	}
+}
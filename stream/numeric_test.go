@@ -0,0 +1,83 @@
+package stream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNumberStreamAggregates(t *testing.T) {
+	if sum := Numbers(Range(1, 101)).Sum(); sum != 5050 {
+		t.Errorf("Expected 5050, got %d", sum)
+	}
+	if sum := OfNumbers[int]().Sum(); sum != 0 {
+		t.Errorf("Expected 0, got %d", sum)
+	}
+	if avg := OfNumbers(1, 2, 3, 4).Average().Get(); avg != 2.5 {
+		t.Errorf("Expected 2.5, got %v", avg)
+	}
+	if OfNumbers[int]().Average().IsPresent() {
+		t.Errorf("Expected empty average for empty stream")
+	}
+	if m := OfNumbers(3, -1, 7).Min().Get(); m != -1 {
+		t.Errorf("Expected -1, got %d", m)
+	}
+	if m := OfNumbers(3, -1, 7).Max().Get(); m != 7 {
+		t.Errorf("Expected 7, got %d", m)
+	}
+	if OfNumbers[float64]().Max().IsPresent() {
+		t.Errorf("Expected empty max for empty stream")
+	}
+	if m := OfNumbers(1, math.NaN(), 3).Max().Get(); !math.IsNaN(m) {
+		t.Errorf("Expected NaN, got %v", m)
+	}
+}
+
+func TestNumberStreamStatistics(t *testing.T) {
+	stats := OfNumbers[uint8](200, 100, 50).Statistics()
+	if stats.Count != 3 || stats.Min != 50 || stats.Max != 200 {
+		t.Errorf("Expected count 3, min 50, max 200, got %+v", stats)
+	}
+	// Sum 按元素类型累加会溢出，Average 按 float64 计算不受影响
+	if stats.Sum != 94 || math.Abs(stats.Average-350.0/3) > 1e-9 {
+		t.Errorf("Expected sum 94 and average 116.67, got %+v", stats)
+	}
+	if empty := OfNumbers[int]().Statistics(); empty != (Statistics[int]{}) {
+		t.Errorf("Expected zero statistics, got %+v", empty)
+	}
+}
+
+func TestNumberStreamChaining(t *testing.T) {
+	words := Of("go", "stream", "api")
+	if sum := MapToNumber(words, func(s string) int { return len(s) }).Filter(func(n int) bool { return n > 2 }).Sum(); sum != 9 {
+		t.Errorf("Expected 9, got %d", sum)
+	}
+	if avg := Numbers(Range(0, 4)).AsFloat().Map(func(f float64) float64 { return f / 2 }).Sum(); avg != 3 {
+		t.Errorf("Expected 3, got %v", avg)
+	}
+	result := OfNumbers(1, 2, 3, 4).CumulativeSum().Boxed().ToSlice()
+	if !equalSlices(result, []int{1, 3, 6, 10}) {
+		t.Errorf("Expected [1 3 6 10], got %v", result)
+	}
+	if size := Numbers(Range(0, 10)).CumulativeSum().EstimateSize(); size != 10 {
+		t.Errorf("Expected 10, got %d", size)
+	}
+}
+
+func TestNumberStreamHistogram(t *testing.T) {
+	counts := OfNumbers(-5, 0, 1, 9, 10, 15, 20, 100).Histogram(0, 10, 20)
+	if !equalSlices(counts, []int64{1, 3, 2, 2}) {
+		t.Errorf("Expected [1 3 2 2], got %v", counts)
+	}
+	if counts := OfNumbers(1.5, 2.5).Histogram(); !equalSlices(counts, []int64{2}) {
+		t.Errorf("Expected [2], got %v", counts)
+	}
+}
+
+func TestNumberStreamDot(t *testing.T) {
+	if dot := OfNumbers(1, 2, 3).Dot(OfNumbers(4, 5, 6, 7)); dot != 32 {
+		t.Errorf("Expected 32, got %d", dot)
+	}
+	if dot := OfNumbers[float64]().Dot(OfNumbers(1.0)); dot != 0 {
+		t.Errorf("Expected 0, got %v", dot)
+	}
+}
@@ -0,0 +1,235 @@
+package stream
+
+import (
+	"expvar"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// StageStats 是单个阶段在一次终端操作中的统计，Index 为 0 表示数据源；
+// Duration、Allocs 等只统计该阶段自身，不包括其上游
+type StageStats struct {
+	Index      int
+	Name       string
+	In         int64
+	Out        int64
+	Duration   time.Duration
+	Allocs     uint64
+	AllocBytes uint64
+	Errors     int64
+}
+
+// PipelineStats 是一次终端操作的完整统计
+type PipelineStats struct {
+	Stages  []StageStats
+	Elapsed time.Duration
+	Err     error
+}
+
+// Observer 在终端操作结束时收到每个阶段以及整条流水线的统计
+type Observer interface {
+	OnStage(stats StageStats)
+	OnPipeline(stats PipelineStats)
+}
+
+type ObserveOption func(*observeConfig)
+
+type observeConfig struct {
+	trackAllocations bool
+}
+
+// TrackAllocations 统计每个阶段的堆分配次数和字节数。
+// 每次拉取元素都需要调用 runtime.ReadMemStats，开销很大，只适合用于诊断；统计的是整个进程的分配
+func TrackAllocations() ObserveOption {
+	return func(c *observeConfig) {
+		c.trackAllocations = true
+	}
+}
+
+type globalObserverConfig struct {
+	observer Observer
+	config   observeConfig
+}
+
+var globalObserver atomic.Pointer[globalObserverConfig]
+
+// SetGlobalObserver 为之后执行终端操作的所有流安装观察者，传入 nil 时移除
+func SetGlobalObserver(observer Observer, opts ...ObserveOption) {
+	if observer == nil {
+		globalObserver.Store(nil)
+		return
+	}
+	cfg := &globalObserverConfig{observer: observer}
+	for _, opt := range opts {
+		opt(&cfg.config)
+	}
+	globalObserver.Store(cfg)
+}
+
+// Observe 为流安装观察者，observer 可以为 nil，此时只收集统计供 Stats 使用
+func (s *streamImpl[T]) Observe(observer Observer, opts ...ObserveOption) Stream[T] {
+	s.checkNotConsumed()
+	s.instrumented = true
+	if observer != nil {
+		s.observers = append(s.observers, observer)
+	}
+	for _, opt := range opts {
+		opt(&s.observeConfig)
+	}
+	return s
+}
+
+// Stats 返回最近一次终端操作的统计，只有安装了观察者的流才会收集
+func (s *streamImpl[T]) Stats() PipelineStats {
+	if s.stats == nil {
+		return PipelineStats{}
+	}
+	return *s.stats
+}
+
+// stageMeter 累积经过某个阶段输出端的数据，统计值包含其全部上游
+type stageMeter struct {
+	out      int64
+	elapsed  time.Duration
+	mallocs  uint64
+	bytes    uint64
+	errors   int64
+	memStats runtime.MemStats
+}
+
+func meter[T any](s *streamImpl[T], upstream iterator[T], m *stageMeter, trackAllocations bool) iterator[T] {
+	return func() (T, bool) {
+		errors := s.errCount
+		var mallocs, bytes uint64
+		if trackAllocations {
+			runtime.ReadMemStats(&m.memStats)
+			mallocs, bytes = m.memStats.Mallocs, m.memStats.TotalAlloc
+		}
+		start := time.Now()
+		item, ok := upstream()
+		m.elapsed += time.Since(start)
+		if trackAllocations {
+			runtime.ReadMemStats(&m.memStats)
+			m.mallocs += m.memStats.Mallocs - mallocs
+			m.bytes += m.memStats.TotalAlloc - bytes
+		}
+		m.errors += s.errCount - errors
+		if ok {
+			m.out++
+		}
+		return item, ok
+	}
+}
+
+// instrument 在数据源和每个中间操作之后插入统计，并在流关闭时通知观察者
+func (s *streamImpl[T]) instrument(source iterator[T], ops []stage[T]) iterator[T] {
+	observers := s.observers
+	cfg := s.observeConfig
+	if global := globalObserver.Load(); global != nil {
+		observers = append(observers[:len(observers):len(observers)], global.observer)
+		cfg.trackAllocations = cfg.trackAllocations || global.config.trackAllocations
+	}
+
+	names := make([]string, 0, len(ops)+1)
+	names = append(names, "source")
+	meters := make([]*stageMeter, len(ops)+1)
+	meters[0] = &stageMeter{}
+	it := meter(s, source, meters[0], cfg.trackAllocations)
+	for i, op := range ops {
+		names = append(names, op.name)
+		meters[i+1] = &stageMeter{}
+		it = meter(s, op.apply(it), meters[i+1], cfg.trackAllocations)
+	}
+
+	start := time.Now()
+	// 最先注册的清理函数最后执行，此时数据源已经关闭，统计中包含完整的错误
+	s.closers = append([]func() error{func() error {
+		stats := PipelineStats{
+			Stages:  make([]StageStats, len(meters)),
+			Elapsed: time.Since(start),
+			Err:     s.err,
+		}
+		var upstream stageMeter
+		for i, m := range meters {
+			stats.Stages[i] = StageStats{
+				Index:      i,
+				Name:       names[i],
+				In:         upstream.out,
+				Out:        m.out,
+				Duration:   m.elapsed - upstream.elapsed,
+				Allocs:     m.mallocs - upstream.mallocs,
+				AllocBytes: m.bytes - upstream.bytes,
+				Errors:     m.errors - upstream.errors,
+			}
+			upstream = *m
+		}
+		s.stats = &stats
+		for _, observer := range observers {
+			for _, stage := range stats.Stages {
+				observer.OnStage(stage)
+			}
+			observer.OnPipeline(stats)
+		}
+		return nil
+	}}, s.closers...)
+	return it
+}
+
+func (s *streamImpl[T]) isInstrumented() bool {
+	return s.instrumented || globalObserver.Load() != nil
+}
+
+// String 以对齐的文本表格输出统计报告
+func (p PipelineStats) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "#\tstage\tin\tout\ttime\tallocs\tbytes\terrors\t")
+	for _, st := range p.Stages {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%s\t%d\t%d\t%d\t\n",
+			st.Index, st.Name, st.In, st.Out, st.Duration, st.Allocs, st.AllocBytes, st.Errors)
+	}
+	w.Flush()
+	fmt.Fprintf(&b, "elapsed: %s", p.Elapsed)
+	if p.Err != nil {
+		fmt.Fprintf(&b, ", error: %v", p.Err)
+	}
+	return b.String()
+}
+
+// ExpvarObserver 将按阶段名称累计的统计发布到 expvar，可通过 /debug/vars 查看
+type ExpvarObserver struct {
+	vars *expvar.Map
+}
+
+// NewExpvarObserver 发布名为 name 的 expvar.Map，同名变量已存在时复用
+func NewExpvarObserver(name string) *ExpvarObserver {
+	if vars, ok := expvar.Get(name).(*expvar.Map); ok {
+		return &ExpvarObserver{vars: vars}
+	}
+	return &ExpvarObserver{vars: expvar.NewMap(name)}
+}
+
+func (o *ExpvarObserver) OnStage(stats StageStats) {
+	o.vars.Add(stats.Name+".in", stats.In)
+	o.vars.Add(stats.Name+".out", stats.Out)
+	o.vars.Add(stats.Name+".nanos", stats.Duration.Nanoseconds())
+	o.vars.Add(stats.Name+".allocs", int64(stats.Allocs))
+	o.vars.Add(stats.Name+".errors", stats.Errors)
+}
+
+func (o *ExpvarObserver) OnPipeline(stats PipelineStats) {
+	o.vars.Add("pipelines", 1)
+	o.vars.Add("nanos", stats.Elapsed.Nanoseconds())
+	if stats.Err != nil {
+		o.vars.Add("failures", 1)
+	}
+}
+
+// Vars 返回发布的 expvar.Map
+func (o *ExpvarObserver) Vars() *expvar.Map {
+	return o.vars
+}
@@ -0,0 +1,164 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type recordingObserver struct {
+	stages    []StageStats
+	pipelines []PipelineStats
+}
+
+func (o *recordingObserver) OnStage(stats StageStats) {
+	o.stages = append(o.stages, stats)
+}
+
+func (o *recordingObserver) OnPipeline(stats PipelineStats) {
+	o.pipelines = append(o.pipelines, stats)
+}
+
+func TestObserve(t *testing.T) {
+	observer := &recordingObserver{}
+	s := Range(0, 10).
+		Observe(observer).
+		Filter(func(n int64) bool { return n%2 == 0 }).
+		Sorted(func(a, b int64) int { return int(b - a) }).
+		Limit(2)
+	result := s.ToSlice()
+
+	if len(result) != 2 || result[0] != 8 || result[1] != 6 {
+		t.Errorf("Expected [8 6], got %v", result)
+	}
+	if len(observer.pipelines) != 1 {
+		t.Fatalf("Expected 1 pipeline, got %d", len(observer.pipelines))
+	}
+	expected := []StageStats{
+		{Index: 0, Name: "source", In: 0, Out: 10},
+		{Index: 1, Name: "Filter", In: 10, Out: 5},
+		{Index: 2, Name: "Sorted+Limit", In: 5, Out: 2},
+	}
+	if len(observer.stages) != len(expected) {
+		t.Fatalf("Expected %d stages, got %d", len(expected), len(observer.stages))
+	}
+	for i, want := range expected {
+		got := observer.stages[i]
+		if got.Index != want.Index || got.Name != want.Name || got.In != want.In || got.Out != want.Out {
+			t.Errorf("Expected stage %+v, got %+v", want, got)
+		}
+	}
+	if stats := s.Stats(); len(stats.Stages) != 3 || stats.Stages[1].Out != 5 {
+		t.Errorf("Expected Stats to match observed stages, got %+v", stats)
+	}
+}
+
+func TestObserveDuration(t *testing.T) {
+	s := Of(1, 2, 3).
+		Observe(nil).
+		Map(func(n int) int { return n }).
+		Peek(func(int) { time.Sleep(5 * time.Millisecond) })
+	s.Count()
+
+	stats := s.Stats()
+	slow, fast := stats.Stages[2].Duration, stats.Stages[1].Duration
+	if slow < 15*time.Millisecond {
+		t.Errorf("Expected Peek to take at least 15ms, got %s", slow)
+	}
+	if fast >= slow {
+		t.Errorf("Expected Map (%s) to be faster than Peek (%s)", fast, slow)
+	}
+	if stats.Elapsed < slow {
+		t.Errorf("Expected elapsed %s to include Peek %s", stats.Elapsed, slow)
+	}
+}
+
+func TestObserveErrors(t *testing.T) {
+	failure := errors.New("broken source")
+	source := newLazyStream("test", func(s *streamImpl[int]) iterator[int] {
+		it := sliceIterator([]int{1, 2})
+		return func() (int, bool) {
+			item, ok := it()
+			if !ok {
+				s.fail(failure)
+			}
+			return item, ok
+		}
+	})
+	s := source.Observe(nil).Filter(func(int) bool { return true })
+	s.Count()
+
+	stats := s.Stats()
+	if stats.Stages[0].Errors != 1 || stats.Stages[1].Errors != 0 {
+		t.Errorf("Expected error attributed to source, got %+v", stats.Stages)
+	}
+	if !errors.Is(stats.Err, failure) {
+		t.Errorf("Expected %v, got %v", failure, stats.Err)
+	}
+	if !strings.Contains(stats.String(), "error: broken source") {
+		t.Errorf("Expected report to contain the error, got:\n%s", stats.String())
+	}
+}
+
+func TestTrackAllocations(t *testing.T) {
+	s := Of(1, 2, 3).
+		Observe(nil, TrackAllocations()).
+		Map(func(n int) int {
+			sink = make([]byte, 1024)
+			return n
+		})
+	s.Count()
+
+	stats := s.Stats()
+	if stats.Stages[1].Allocs < 3 || stats.Stages[1].AllocBytes < 3*1024 {
+		t.Errorf("Expected at least 3 allocations of 1KiB in Map, got %+v", stats.Stages[1])
+	}
+}
+
+var sink []byte
+
+func TestStatsWithoutObserver(t *testing.T) {
+	s := Of(1, 2, 3).Filter(func(int) bool { return true })
+	s.Count()
+	if stats := s.Stats(); stats.Stages != nil {
+		t.Errorf("Expected empty stats, got %+v", stats)
+	}
+}
+
+func TestGlobalObserver(t *testing.T) {
+	observer := &recordingObserver{}
+	SetGlobalObserver(observer)
+	defer SetGlobalObserver(nil)
+
+	Of(1, 2, 3).Map(func(n int) int { return n }).Count()
+	Of("a").Count()
+
+	if len(observer.pipelines) != 2 {
+		t.Errorf("Expected 2 pipelines, got %d", len(observer.pipelines))
+	}
+}
+
+// expvarRuns 使每次运行（如 go test -count=3）发布到不同的全局变量名
+var expvarRuns atomic.Int32
+
+func TestExpvarObserver(t *testing.T) {
+	name := fmt.Sprintf("%s_%d", t.Name(), expvarRuns.Add(1))
+	observer := NewExpvarObserver(name)
+	for i := 0; i < 2; i++ {
+		Of(1, 2, 3).Observe(observer).Filter(func(n int) bool { return n > 1 }).Count()
+	}
+
+	vars := NewExpvarObserver(name).Vars()
+	if v := vars.Get("Filter.in"); v == nil || v.String() != "6" {
+		t.Errorf("Expected Filter.in=6, got %v", v)
+	}
+	if v := vars.Get("Filter.out"); v == nil || v.String() != "4" {
+		t.Errorf("Expected Filter.out=4, got %v", v)
+	}
+	if v := vars.Get("pipelines"); v == nil || v.String() != "2" {
+		t.Errorf("Expected pipelines=2, got %v", v)
+	}
+}
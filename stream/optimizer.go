@@ -0,0 +1,223 @@
+package stream
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+var optimizationsDisabled atomic.Bool
+
+// SetOptimizations 开启或关闭执行前的流水线改写，默认开启；关闭后流水线严格按照书写的阶段执行
+func SetOptimizations(enabled bool) {
+	optimizationsDisabled.Store(!enabled)
+}
+
+// Unoptimized 使这个流严格按照书写的阶段执行，用于调试以及对比优化前后的结果
+func (s *streamImpl[T]) Unoptimized() Stream[T] {
+	s.checkNotConsumed()
+	s.unoptimized = true
+	return s
+}
+
+// optimizedPlan 返回改写后的数据源和中间操作，不修改流本身：
+//   - 紧跟在切片或区间数据源之后的 Skip 和 Limit 直接调整数据源的下标区间
+//   - 上游已经按相同的排序依据（见 SortedNatural）排好序时丢弃 Sorted，上游已经两两不同时丢弃 Distinct
+//   - Sorted 紧跟 Limit 改写为用有界堆选出前 k 个元素
+//   - 相邻的 Map 和 Filter 合并为一个阶段
+func (s *streamImpl[T]) optimizedPlan() (stageInfo, window, []stage[T]) {
+	origin, bounds, ops := s.origin, window{from: 0, to: s.size}, s.operations
+	if len(ops) == 0 || s.unoptimized || optimizationsDisabled.Load() {
+		return origin, bounds, ops
+	}
+	if s.open == nil {
+		origin, bounds, ops = pushIntoSource(origin, bounds, ops)
+	}
+	ops = dropRedundant(ops, s.sourceCharacteristics())
+	ops = rewriteSortedLimit(ops)
+	ops = fuseMapFilter(ops)
+	return origin, bounds, ops
+}
+
+// window 是切片或区间数据源中实际产生元素的下标区间 [from, to)
+type window struct {
+	from, to int64
+}
+
+func pushIntoSource[T any](origin stageInfo, bounds window, ops []stage[T]) (stageInfo, window, []stage[T]) {
+	names := []string{origin.name}
+	i := 0
+	for ; i < len(ops); i++ {
+		n := min(max(ops[i].n, 0), bounds.to-bounds.from)
+		switch ops[i].name {
+		case "Skip":
+			bounds.from += n
+		case "Limit":
+			bounds.to = bounds.from + n
+		default:
+			return renamed(origin, names), bounds, ops[i:]
+		}
+		names = append(names, ops[i].name)
+		origin.fused = append(origin.fused, ops[i].callers)
+		origin.fused = append(origin.fused, ops[i].fused...)
+	}
+	return renamed(origin, names), bounds, ops[i:]
+}
+
+func renamed(info stageInfo, names []string) stageInfo {
+	info.name = strings.Join(names, "+")
+	return info
+}
+
+// dropRedundant 按上游的特征值丢弃不会改变结果的 Sorted 和 Distinct，c 为数据源的特征值
+func dropRedundant[T any](ops []stage[T], c Characteristics[T]) []stage[T] {
+	result := make([]stage[T], 0, len(ops))
+	for _, op := range ops {
+		switch {
+		case op.name == "Sorted" && c.Has(Sorted) && op.ordering != nil && op.ordering == c.ordering:
+			// Sorted 是稳定排序，已经有序的元素排序后不变
+			continue
+		case op.name == "Distinct" && c.Has(Distinct):
+			continue
+		}
+		c, _ = c.after(op, -1)
+		result = append(result, op)
+	}
+	return result
+}
+
+func rewriteSortedLimit[T any](ops []stage[T]) []stage[T] {
+	result := make([]stage[T], 0, len(ops))
+	for i := 0; i < len(ops); i++ {
+		if ops[i].name == "Sorted" && i+1 < len(ops) && ops[i+1].name == "Limit" {
+			comparator, k := ops[i].comparator, ops[i+1].n
+			info := merged(ops[i : i+2])
+			info.shortCircuit = false
+			result = append(result, stage[T]{stageInfo: info, apply: func(upstream iterator[T]) iterator[T] {
+				var result iterator[T]
+				return func() (T, bool) {
+					if result == nil {
+						result = sliceIterator(smallestK(upstream, k, comparator))
+					}
+					return result()
+				}
+			}})
+			i++
+			continue
+		}
+		result = append(result, ops[i])
+	}
+	return result
+}
+
+// smallestK 返回按 comparator 升序排列的最小 k 个元素，相等的元素保持出现顺序，与稳定排序后取前 k 个相同
+func smallestK[T any](it iterator[T], k int64, comparator Comparator[T]) []T {
+	type indexed struct {
+		item  T
+		index int
+	}
+	var index int
+	indexedIt := func() (indexed, bool) {
+		item, ok := it()
+		index++
+		return indexed{item: item, index: index}, ok
+	}
+	largest := topK(indexedIt, int(min(k, int64(^uint(0)>>1))), func(a, b indexed) int {
+		if c := comparator(b.item, a.item); c != 0 {
+			return c
+		}
+		return b.index - a.index
+	})
+	result := make([]T, len(largest))
+	for i, v := range largest {
+		result[i] = v.item
+	}
+	return result
+}
+
+func fuseMapFilter[T any](ops []stage[T]) []stage[T] {
+	result := make([]stage[T], 0, len(ops))
+	for i := 0; i < len(ops); {
+		j := i
+		for j < len(ops) && (ops[j].name == "Map" || ops[j].name == "Filter") {
+			j++
+		}
+		if j-i < 2 {
+			result = append(result, ops[i])
+			i++
+			continue
+		}
+		result = append(result, fuse(ops[i:j]))
+		i = j
+	}
+	return result
+}
+
+// fuse 把一串 Map 和 Filter 合并为一个阶段，每拉取一个元素时依次执行
+func fuse[T any](ops []stage[T]) stage[T] {
+	predicates := make([]Predicate[T], len(ops))
+	mappers := make([]Function[T, T], len(ops))
+	for i, op := range ops {
+		predicates[i], mappers[i] = op.predicate, op.mapper
+	}
+	return stage[T]{stageInfo: merged(ops), apply: func(upstream iterator[T]) iterator[T] {
+		return func() (T, bool) {
+		next:
+			for item, ok := upstream(); ok; item, ok = upstream() {
+				for i, predicate := range predicates {
+					if predicate == nil {
+						item = mappers[i](item)
+					} else if !predicate(item) {
+						continue next
+					}
+				}
+				return item, true
+			}
+			var zero T
+			return zero, false
+		}
+	}}
+}
+
+// merged 合并多个阶段的描述：名称和标签以 "+" 和 ", " 连接，保留每个阶段的创建位置
+func merged[T any](ops []stage[T]) stageInfo {
+	info := ops[0].stageInfo
+	names := make([]string, len(ops))
+	labels := make([]string, 0)
+	info.fused = nil
+	for i, op := range ops {
+		names[i] = op.name
+		if op.label != "" {
+			labels = append(labels, op.label)
+		}
+		if i > 0 {
+			info.fused = append(info.fused, op.callers)
+		}
+		info.fused = append(info.fused, op.fused...)
+		info.barrier = info.barrier || op.barrier
+		info.shortCircuit = info.shortCircuit || op.shortCircuit
+	}
+	info.name = strings.Join(names, "+")
+	info.label = strings.Join(labels, ", ")
+	return info
+}
+
+// knownSize 在不执行流水线的情况下计算元素个数，只适用于切片和区间数据源以及不改变元素个数或个数可以直接算出的阶段；
+// 设置了 Recover 或 OnPanic 时阶段中的 panic 会改变元素个数或产生错误，必须真正执行
+func (s *streamImpl[T]) knownSize() (int64, bool) {
+	if s.open != nil || s.traits&Sized == 0 || s.unoptimized || optimizationsDisabled.Load() || s.isInstrumented() || s.panicHandler != nil {
+		return 0, false
+	}
+	size := s.size
+	for _, op := range s.operations {
+		switch op.name {
+		case "Map", "Sorted":
+		case "Limit":
+			size = min(size, max(op.n, 0))
+		case "Skip":
+			size = max(size-max(op.n, 0), 0)
+		default:
+			return 0, false
+		}
+	}
+	return size, true
+}
@@ -0,0 +1,173 @@
+package stream
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOptimizedResultsMatchUnoptimized(t *testing.T) {
+	data := []int{7, 3, 9, 3, 1, 8, 2, 9, 5, 4, 6, 1}
+	ascending := func(a, b int) int { return a - b }
+	descending := func(a, b int) int { return b - a }
+	pipelines := map[string]func(Stream[int]) Stream[int]{
+		"fused map and filter": func(s Stream[int]) Stream[int] {
+			return s.Filter(func(n int) bool { return n > 2 }).Map(func(n int) int { return n * 2 }).Filter(func(n int) bool { return n%3 != 0 })
+		},
+		"sorted limit": func(s Stream[int]) Stream[int] {
+			return s.Sorted(ascending).Limit(4)
+		},
+		"sorted limit beyond size": func(s Stream[int]) Stream[int] {
+			return s.Sorted(descending).Limit(100)
+		},
+		"skip and limit pushed into source": func(s Stream[int]) Stream[int] {
+			return s.Skip(2).Limit(5).Map(func(n int) int { return -n })
+		},
+		"negative limit and skip": func(s Stream[int]) Stream[int] {
+			return s.Skip(-1).Limit(-1)
+		},
+		"redundant sort": func(s Stream[int]) Stream[int] {
+			return s.Sorted(ascending).Filter(func(n int) bool { return n != 9 }).Limit(8).Sorted(ascending)
+		},
+		"sort after sort": func(s Stream[int]) Stream[int] {
+			return s.Sorted(ascending).Sorted(descending)
+		},
+		"redundant distinct": func(s Stream[int]) Stream[int] {
+			return s.Distinct().Filter(func(n int) bool { return n > 1 }).Distinct()
+		},
+	}
+
+	for name, build := range pipelines {
+		optimized := build(OfSlice(data)).ToSlice()
+		unoptimized := build(OfSlice(data).Unoptimized()).ToSlice()
+		if !equalSlices(optimized, unoptimized) {
+			t.Errorf("%s: expected %v, got %v", name, unoptimized, optimized)
+		}
+		count := build(OfSlice(data)).Count()
+		if count != int64(len(unoptimized)) {
+			t.Errorf("%s: expected count %d, got %d", name, len(unoptimized), count)
+		}
+	}
+}
+
+func TestOptimizedPlan(t *testing.T) {
+	sorted := SortedNatural(Of(5, 1, 4, 1, 3).Skip(1).Limit(4))
+	s := SortedNatural(sorted.Distinct().Distinct().Filter(func(n int) bool { return n > 1 })).
+		Map(func(n int) int { return n * 10 }).
+		Sorted(func(a, b int) int { return b - a }).
+		Limit(2)
+
+	expected := "Of+Skip+Limit\nSorted [barrier]\nDistinct\nFilter+Map\nSorted+Limit [barrier]\n"
+	if plan := s.Explain(); plan != expected {
+		t.Errorf("Expected plan:\n%s\ngot:\n%s", expected, plan)
+	}
+	if result := s.ToSlice(); !equalSlices(result, []int{40, 30}) {
+		t.Errorf("Expected [40 30], got %v", result)
+	}
+}
+
+func TestOptimizedSortsKeepOrderOfEqualElements(t *testing.T) {
+	type item struct {
+		key  int
+		name string
+	}
+	items := []item{{1, "a"}, {0, "b"}, {1, "c"}, {0, "d"}}
+	byKey := func(a, b item) int { return a.key - b.key }
+	byName := func(a, b item) int { return strings.Compare(b.name, a.name) }
+	pipelines := map[string]func(Stream[item]) Stream[item]{
+		"sorted limit": func(s Stream[item]) Stream[item] {
+			return s.Sorted(byKey).Limit(4)
+		},
+		"sorted limit fewer": func(s Stream[item]) Stream[item] {
+			return s.Sorted(byKey).Limit(3)
+		},
+		"sort after sort": func(s Stream[item]) Stream[item] {
+			return s.Sorted(byName).Sorted(byKey)
+		},
+		"same sort twice": func(s Stream[item]) Stream[item] {
+			return s.Sorted(byKey).Skip(1).Sorted(byKey)
+		},
+	}
+
+	for name, build := range pipelines {
+		optimized := build(OfSlice(items)).ToSlice()
+		unoptimized := build(OfSlice(items).Unoptimized()).ToSlice()
+		if !equalSlices(optimized, unoptimized) {
+			t.Errorf("%s: expected %v, got %v", name, unoptimized, optimized)
+		}
+	}
+
+	expected := []item{{0, "b"}, {0, "d"}, {1, "a"}, {1, "c"}}
+	if result := OfSlice(items).Sorted(byKey).ToSlice(); !equalSlices(result, expected) {
+		t.Errorf("Expected stable sort %v, got %v", expected, result)
+	}
+}
+
+func TestDropSortOnSortedSource(t *testing.T) {
+	s := SortedNatural(RangeStep(0, 10, 2).Filter(func(n int) bool { return n != 4 }))
+	if plan := s.Explain(); plan != "RangeStep\nFilter\n" {
+		t.Errorf("Expected Sorted to be dropped, got:\n%s", plan)
+	}
+	if result := s.ToSlice(); !equalSlices(result, []int{0, 2, 6, 8}) {
+		t.Errorf("Expected [0 2 6 8], got %v", result)
+	}
+
+	// 降序的区间和按比较器排序都不能省略
+	descending := SortedNatural(RangeStep(10, 0, -2))
+	if result := descending.ToSlice(); !equalSlices(result, []int{2, 4, 6, 8, 10}) {
+		t.Errorf("Expected [2 4 6 8 10], got %v", result)
+	}
+	byValue := func(a, b int) int { return a - b }
+	if plan := RangeStep(0, 10, 2).Sorted(byValue).Sorted(byValue).Explain(); strings.Count(plan, "Sorted") != 2 {
+		t.Errorf("Expected sorts by comparator to be kept, got:\n%s", plan)
+	}
+}
+
+func TestCountWithoutIterating(t *testing.T) {
+	calls := 0
+	count := Of(1, 2, 3, 4, 5).Map(func(n int) int {
+		calls++
+		return n
+	}).Skip(1).Count()
+
+	if count != 4 {
+		t.Errorf("Expected 4, got %d", count)
+	}
+	if calls != 0 {
+		t.Errorf("Expected mapper not to be called, got %d calls", calls)
+	}
+
+	count = Of(1, 2, 3).Peek(func(int) { calls++ }).Count()
+	if count != 3 || calls != 3 {
+		t.Errorf("Expected Peek to run for each element, got count %d and %d calls", count, calls)
+	}
+}
+
+func TestInstrumentationKeepsOptimizations(t *testing.T) {
+	build := func() Stream[int] {
+		return Of(1, 2, 3, 4).Filter(func(n int) bool { return n > 1 }).Map(func(n int) int { return n * 2 })
+	}
+	observed := build().Observe(nil)
+	if plan := observed.Explain(); plan != build().Explain() || !strings.Contains(plan, "Filter+Map") {
+		t.Errorf("Expected observers not to change the plan, got:\n%s", plan)
+	}
+	observed.ToSlice()
+	if stages := observed.Stats().Stages; len(stages) != 2 || stages[1].Name != "Filter+Map" || stages[1].Out != 3 {
+		t.Errorf("Expected stats for the optimized stages, got %+v", stages)
+	}
+
+	SetCallSiteTracking(true)
+	defer SetCallSiteTracking(false)
+	if plan := build().Explain(); !strings.Contains(plan, "Filter+Map @ optimizer_test.go:") {
+		t.Errorf("Expected call sites on the optimized plan, got:\n%s", plan)
+	}
+}
+
+func TestSetOptimizations(t *testing.T) {
+	SetOptimizations(false)
+	defer SetOptimizations(true)
+
+	plan := Of(1, 2, 3).Filter(func(n int) bool { return n > 1 }).Map(func(n int) int { return n }).Explain()
+	if strings.Contains(plan, "+") {
+		t.Errorf("Expected unoptimized plan, got:\n%s", plan)
+	}
+}
@@ -16,6 +16,35 @@ func EmptyOptional[T any]() Optional[T] {
 	return Optional[T]{value: nil}
 }
 
+// OptionalFromPair 把 Go 中常见的 (value, ok) 返回值转换为 Optional，ok 为 false 时为空
+func OptionalFromPair[T any](value T, ok bool) Optional[T] {
+	if !ok {
+		return EmptyOptional[T]()
+	}
+	return OfOptional(value)
+}
+
+// OptionalFromErr 把 (value, err) 返回值转换为 Optional，err 不为 nil 时为空
+func OptionalFromErr[T any](value T, err error) Optional[T] {
+	return OptionalFromPair(value, err == nil)
+}
+
+// MapOptional 在有值时用 mapper 转换该值，否则返回空的 Optional
+func MapOptional[T, R any](o Optional[T], mapper Function[T, R]) Optional[R] {
+	if o.value == nil {
+		return EmptyOptional[R]()
+	}
+	return OfOptional(mapper(*o.value))
+}
+
+// FlatMapOptional 在有值时返回 mapper 的结果，否则返回空的 Optional
+func FlatMapOptional[T, R any](o Optional[T], mapper Function[T, Optional[R]]) Optional[R] {
+	if o.value == nil {
+		return EmptyOptional[R]()
+	}
+	return mapper(*o.value)
+}
+
 func (o Optional[T]) IsPresent() bool {
 	return o.value != nil
 }
@@ -24,6 +53,7 @@ func (o Optional[T]) IsEmpty() bool {
 	return o.value == nil
 }
 
+// Get 返回其中的值，为空时返回零值；需要区分两种情况时使用 Lookup
 func (o Optional[T]) Get() T {
 	if o.value == nil {
 		var zero T
@@ -32,6 +62,15 @@ func (o Optional[T]) Get() T {
 	return *o.value
 }
 
+// Lookup 返回其中的值以及是否有值，与 map 查找的 v, ok 写法一致
+func (o Optional[T]) Lookup() (T, bool) {
+	if o.value == nil {
+		var zero T
+		return zero, false
+	}
+	return *o.value, true
+}
+
 func (o Optional[T]) OrElse(other T) T {
 	if o.value == nil {
 		return other
@@ -53,6 +92,23 @@ func (o Optional[T]) OrElsePanic(msg string) T {
 	return *o.value
 }
 
+// OrElseErr 有值时返回该值和 nil，否则返回零值和 err
+func (o Optional[T]) OrElseErr(err error) (T, error) {
+	if o.value == nil {
+		var zero T
+		return zero, err
+	}
+	return *o.value, nil
+}
+
+// Or 有值时返回自身，否则返回 supplier 提供的 Optional
+func (o Optional[T]) Or(supplier Supplier[Optional[T]]) Optional[T] {
+	if o.value == nil {
+		return supplier()
+	}
+	return o
+}
+
 func (o Optional[T]) IfPresent(consumer Consumer[T]) {
 	if o.value != nil {
 		consumer(*o.value)
@@ -73,3 +129,19 @@ func (o Optional[T]) Filter(predicate Predicate[T]) Optional[T] {
 	}
 	return o
 }
+
+// ToStream 有值时返回只包含该值的流，否则返回空流
+func (o Optional[T]) ToStream() Stream[T] {
+	if o.value == nil {
+		return Empty[T]()
+	}
+	return Of(*o.value)
+}
+
+// Equals 判断两个 Optional 是否都为空，或者都有值且值相等；与 Distinct 一样使用 == 比较，值的类型不可比较时 panic
+func (o Optional[T]) Equals(other Optional[T]) bool {
+	if o.value == nil || other.value == nil {
+		return o.value == nil && other.value == nil
+	}
+	return any(*o.value) == any(*other.value)
+}
@@ -0,0 +1,189 @@
+package stream
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// MarshalJSON 把空的 Optional 编码为 null，否则按其中的值编码
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if o.value == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(*o.value)
+}
+
+// UnmarshalJSON 把 null 解码为空的 Optional；字段缺失时 Optional 保持为空
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = EmptyOptional[T]()
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*o = OfOptional(value)
+	return nil
+}
+
+// Scan 实现 sql.Scanner，NULL 对应空的 Optional，其余值按 database/sql 的常见规则转换为 T
+func (o *Optional[T]) Scan(src any) error {
+	if src == nil {
+		*o = EmptyOptional[T]()
+		return nil
+	}
+	var value T
+	if scanner, ok := any(&value).(sql.Scanner); ok {
+		if err := scanner.Scan(src); err != nil {
+			return err
+		}
+	} else if err := assignScanned(reflect.ValueOf(&value).Elem(), src); err != nil {
+		return err
+	}
+	*o = OfOptional(value)
+	return nil
+}
+
+// Value 实现 driver.Valuer，空的 Optional 写入 NULL
+func (o Optional[T]) Value() (driver.Value, error) {
+	if o.value == nil {
+		return nil, nil
+	}
+	if valuer, ok := any(*o.value).(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	return driver.DefaultParameterConverter.ConvertValue(*o.value)
+}
+
+// MarshalText 把空的 Optional 编码为空文本，支持实现了 encoding.TextMarshaler 的类型和基本类型
+func (o Optional[T]) MarshalText() ([]byte, error) {
+	if o.value == nil {
+		return []byte{}, nil
+	}
+	if marshaler, ok := any(*o.value).(encoding.TextMarshaler); ok {
+		return marshaler.MarshalText()
+	}
+	text, ok := formatText(reflect.ValueOf(*o.value))
+	if !ok {
+		return nil, fmt.Errorf("stream: cannot marshal %T as text", *o.value)
+	}
+	return []byte(text), nil
+}
+
+// UnmarshalText 把空文本解码为空的 Optional，因此 Optional[string] 无法通过文本表示空字符串
+func (o *Optional[T]) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*o = EmptyOptional[T]()
+		return nil
+	}
+	var value T
+	if unmarshaler, ok := any(&value).(encoding.TextUnmarshaler); ok {
+		if err := unmarshaler.UnmarshalText(text); err != nil {
+			return err
+		}
+	} else if err := parseText(reflect.ValueOf(&value).Elem(), string(text)); err != nil {
+		return err
+	}
+	*o = OfOptional(value)
+	return nil
+}
+
+func (o Optional[T]) String() string {
+	if o.value == nil {
+		return "Optional.empty"
+	}
+	return fmt.Sprintf("Optional[%v]", *o.value)
+}
+
+func (o Optional[T]) GoString() string {
+	name := reflect.TypeOf((*T)(nil)).Elem().String()
+	if o.value == nil {
+		return fmt.Sprintf("stream.EmptyOptional[%s]()", name)
+	}
+	return fmt.Sprintf("stream.OfOptional[%s](%#v)", name, *o.value)
+}
+
+// assignScanned 把驱动返回的值赋给 dest：类型可以直接赋值时直接赋值，
+// 字节和字符串按文本解析，数值和布尔值先格式化再解析，从而检查溢出和精度丢失
+func assignScanned(dest reflect.Value, src any) error {
+	switch v := src.(type) {
+	case []byte:
+		if dest.Kind() == reflect.Slice && dest.Type().Elem().Kind() == reflect.Uint8 {
+			// 驱动可能复用该缓冲区，必须复制
+			dest.SetBytes(bytes.Clone(v))
+			return nil
+		}
+		return parseText(dest, string(v))
+	case string:
+		if dest.Kind() == reflect.Slice && dest.Type().Elem().Kind() == reflect.Uint8 {
+			dest.SetBytes([]byte(v))
+			return nil
+		}
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dest.Type()) {
+		dest.Set(sv)
+		return nil
+	}
+	if text, ok := formatText(sv); ok {
+		return parseText(dest, text)
+	}
+	return fmt.Errorf("stream: cannot scan %T into %s", src, dest.Type())
+}
+
+func formatText(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), true
+	}
+	return "", false
+}
+
+func parseText(dest reflect.Value, text string) error {
+	var err error
+	switch dest.Kind() {
+	case reflect.String:
+		dest.SetString(text)
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(text); err == nil {
+			dest.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if n, err = strconv.ParseInt(text, 10, dest.Type().Bits()); err == nil {
+			dest.SetInt(n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n uint64
+		if n, err = strconv.ParseUint(text, 10, dest.Type().Bits()); err == nil {
+			dest.SetUint(n)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(text, dest.Type().Bits()); err == nil {
+			dest.SetFloat(f)
+		}
+	default:
+		return fmt.Errorf("stream: cannot convert %q into %s", text, dest.Type())
+	}
+	if err != nil {
+		return fmt.Errorf("stream: cannot convert %q into %s: %w", text, dest.Type(), err)
+	}
+	return nil
+}
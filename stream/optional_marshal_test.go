@@ -0,0 +1,136 @@
+package stream
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type optionalDTO struct {
+	Name  string           `json:"name"`
+	Age   Optional[int]    `json:"age"`
+	Email Optional[string] `json:"email"`
+}
+
+func TestOptionalJSON(t *testing.T) {
+	data, err := json.Marshal(optionalDTO{Name: "alice", Age: OfOptional(30)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"name":"alice","age":30,"email":null}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	var dto optionalDTO
+	if err := json.Unmarshal([]byte(`{"name":"bob","email":null,"age":7}`), &dto); err != nil {
+		t.Fatal(err)
+	}
+	if dto.Age.Get() != 7 || dto.Email.IsPresent() {
+		t.Errorf("Unexpected DTO: %v", dto)
+	}
+
+	dto = optionalDTO{Email: OfOptional("stale")}
+	if err := json.Unmarshal([]byte(`{"email":null}`), &dto); err != nil {
+		t.Fatal(err)
+	}
+	if dto.Email.IsPresent() || dto.Age.IsPresent() {
+		t.Errorf("Expected null and missing fields to be empty, got %v", dto)
+	}
+
+	if err := json.Unmarshal([]byte(`{"age":"x"}`), &dto); err == nil {
+		t.Errorf("Expected error for mismatched type")
+	}
+}
+
+type optionalRow struct {
+	ID    int32            `db:"id"`
+	Email Optional[string] `db:"email"`
+	Score Optional[int8]   `db:"score"`
+}
+
+func TestOptionalSQL(t *testing.T) {
+	d := &fakeDriver{
+		columns: []string{"id", "email", "score"},
+		rows: [][]driver.Value{
+			{int64(1), []byte("a@example.com"), int64(9)},
+			{int64(2), nil, nil},
+		},
+	}
+	rows := FromQuery[optionalRow](context.Background(), openFakeDB(t, d), "SELECT * FROM users").ToSlice()
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %v", rows)
+	}
+	if rows[0].Email.Get() != "a@example.com" || rows[0].Score.Get() != 9 {
+		t.Errorf("Unexpected first row: %v", rows[0])
+	}
+	if rows[1].Email.IsPresent() || rows[1].Score.IsPresent() {
+		t.Errorf("Expected NULL columns to be empty, got %v", rows[1])
+	}
+
+	var score Optional[int8]
+	if err := score.Scan(int64(300)); err == nil {
+		t.Errorf("Expected overflow error, got %v", score)
+	}
+	var at Optional[time.Time]
+	now := time.Now()
+	if err := at.Scan(now); err != nil || !at.Get().Equal(now) {
+		t.Errorf("Expected %v, got %v, %v", now, at, err)
+	}
+}
+
+func TestOptionalDriverValue(t *testing.T) {
+	if v, err := EmptyOptional[string]().Value(); v != nil || err != nil {
+		t.Errorf("Expected nil, got %v, %v", v, err)
+	}
+	if v, err := OfOptional(int32(5)).Value(); v != int64(5) || err != nil {
+		t.Errorf("Expected int64(5), got %#v, %v", v, err)
+	}
+	if v, err := OfOptional(OfOptional("nested")).Value(); v != "nested" || err != nil {
+		t.Errorf("Expected nested valuer to be used, got %#v, %v", v, err)
+	}
+}
+
+func TestOptionalText(t *testing.T) {
+	text, err := OfOptional(2.5).MarshalText()
+	if err != nil || string(text) != "2.5" {
+		t.Errorf("Expected 2.5, got %s, %v", text, err)
+	}
+	if text, _ := EmptyOptional[int]().MarshalText(); len(text) != 0 {
+		t.Errorf("Expected empty text, got %s", text)
+	}
+
+	var n Optional[uint16]
+	if err := n.UnmarshalText([]byte("8080")); err != nil || n.Get() != 8080 {
+		t.Errorf("Expected 8080, got %v, %v", n, err)
+	}
+	if err := n.UnmarshalText(nil); err != nil || n.IsPresent() {
+		t.Errorf("Expected empty optional, got %v", n)
+	}
+	var at Optional[time.Time]
+	if err := at.UnmarshalText([]byte("2024-01-02T03:04:05Z")); err != nil || at.Get().Year() != 2024 {
+		t.Errorf("Expected 2024, got %v, %v", at, err)
+	}
+
+	m := map[Optional[string]]int{OfOptional("a"): 1}
+	if data, err := json.Marshal(m); err != nil || string(data) != `{"a":1}` {
+		t.Errorf(`Expected {"a":1}, got %s, %v`, data, err)
+	}
+}
+
+func TestOptionalString(t *testing.T) {
+	if s := fmt.Sprint(OfOptional(42)); s != "Optional[42]" {
+		t.Errorf("Expected Optional[42], got %s", s)
+	}
+	if s := fmt.Sprint(EmptyOptional[int]()); s != "Optional.empty" {
+		t.Errorf("Expected Optional.empty, got %s", s)
+	}
+	if s := fmt.Sprintf("%#v", OfOptional("x")); s != `stream.OfOptional[string]("x")` {
+		t.Errorf(`Expected stream.OfOptional[string]("x"), got %s`, s)
+	}
+	if s := fmt.Sprintf("%#v", EmptyOptional[int]()); s != "stream.EmptyOptional[int]()" {
+		t.Errorf("Expected stream.EmptyOptional[int](), got %s", s)
+	}
+}
@@ -0,0 +1,84 @@
+package stream
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestOptionalFromPair(t *testing.T) {
+	m := map[string]int{"a": 1}
+	v, ok := m["a"]
+	if v, ok := OptionalFromPair(v, ok).Lookup(); !ok || v != 1 {
+		t.Errorf("Expected 1, got %d, %v", v, ok)
+	}
+	v, ok = m["b"]
+	if OptionalFromPair(v, ok).IsPresent() {
+		t.Errorf("Expected empty optional for missing key")
+	}
+
+	if n := OptionalFromErr(strconv.Atoi("42")).OrElse(-1); n != 42 {
+		t.Errorf("Expected 42, got %d", n)
+	}
+	if n := OptionalFromErr(strconv.Atoi("x")).OrElse(-1); n != -1 {
+		t.Errorf("Expected -1, got %d", n)
+	}
+}
+
+func TestMapOptional(t *testing.T) {
+	if s := MapOptional(OfOptional(42), strconv.Itoa).OrElse(""); s != "42" {
+		t.Errorf("Expected \"42\", got %q", s)
+	}
+	called := false
+	MapOptional(EmptyOptional[int](), func(n int) string { called = true; return "" })
+	if called {
+		t.Errorf("Expected mapper not to be called on empty optional")
+	}
+
+	parse := func(s string) Optional[int] { return OptionalFromErr(strconv.Atoi(s)) }
+	if n := FlatMapOptional(OfOptional("7"), parse).OrElse(0); n != 7 {
+		t.Errorf("Expected 7, got %d", n)
+	}
+	if FlatMapOptional(OfOptional("x"), parse).IsPresent() {
+		t.Errorf("Expected empty optional")
+	}
+}
+
+func TestOptionalOrAndErr(t *testing.T) {
+	fallback := func() Optional[int] { return OfOptional(2) }
+	if n := OfOptional(1).Or(fallback).Get(); n != 1 {
+		t.Errorf("Expected 1, got %d", n)
+	}
+	if n := EmptyOptional[int]().Or(fallback).Get(); n != 2 {
+		t.Errorf("Expected 2, got %d", n)
+	}
+
+	notFound := errors.New("not found")
+	if _, err := EmptyOptional[int]().OrElseErr(notFound); err != notFound {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+	if n, err := Of(3, 4).FindFirst().OrElseErr(notFound); err != nil || n != 3 {
+		t.Errorf("Expected 3, got %d, %v", n, err)
+	}
+	if v, ok := EmptyOptional[string]().Lookup(); ok || v != "" {
+		t.Errorf("Expected zero value and false, got %q, %v", v, ok)
+	}
+}
+
+func TestOptionalToStream(t *testing.T) {
+	if result := OfOptional(5).ToStream().ToSlice(); !equalSlices(result, []int{5}) {
+		t.Errorf("Expected [5], got %v", result)
+	}
+	if count := EmptyOptional[int]().ToStream().Count(); count != 0 {
+		t.Errorf("Expected 0, got %d", count)
+	}
+}
+
+func TestOptionalEquals(t *testing.T) {
+	if !OfOptional("a").Equals(OfOptional("a")) || !EmptyOptional[string]().Equals(EmptyOptional[string]()) {
+		t.Errorf("Expected equal optionals")
+	}
+	if OfOptional("a").Equals(OfOptional("b")) || OfOptional("a").Equals(EmptyOptional[string]()) {
+		t.Errorf("Expected different optionals")
+	}
+}
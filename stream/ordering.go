@@ -0,0 +1,36 @@
+package stream
+
+import "cmp"
+
+// NaturalOrder 返回按 cmp.Compare 自然顺序比较的比较器
+func NaturalOrder[T cmp.Ordered]() Comparator[T] {
+	return func(a, b T) int {
+		return cmp.Compare(a, b)
+	}
+}
+
+// naturalOrdering 标识按自然顺序排序，用于判断上游是否已经按自然顺序有序
+type naturalOrdering struct {
+	descending bool
+}
+
+// SortedNatural 按自然顺序升序排序，与 Sorted(NaturalOrder[T]()) 相同；
+// 上游已知按自然顺序升序排列（如步长为正的 Range）时，优化器会省略这次排序
+func SortedNatural[T cmp.Ordered](s Stream[T]) Stream[T] {
+	return s.sortWith(NaturalOrder[T](), naturalOrdering{})
+}
+
+// Comparing 根据提取出的键构造比较器
+func Comparing[T any, K cmp.Ordered](key Function[T, K]) Comparator[T] {
+	return func(a, b T) int {
+		return cmp.Compare(key(a), key(b))
+	}
+}
+
+func MaxBy[T any, K cmp.Ordered](s Stream[T], key Function[T, K]) Optional[T] {
+	return s.Max(Comparing(key))
+}
+
+func MinBy[T any, K cmp.Ordered](s Stream[T], key Function[T, K]) Optional[T] {
+	return s.Min(Comparing(key))
+}
@@ -0,0 +1,130 @@
+package stream
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+)
+
+// PageFetcher 根据游标获取一页数据，返回下一页的游标以及是否已经是最后一页
+type PageFetcher[T, C any] func(ctx context.Context, cursor C) (items []T, next C, done bool, err error)
+
+type PageOption func(*pageConfig)
+
+type pageConfig struct {
+	prefetch    bool
+	maxAttempts int
+	backoff     time.Duration
+	retryable   func(error) bool
+}
+
+// WithPrefetch 在下游消费当前页时于后台预取下一页
+func WithPrefetch() PageOption {
+	return func(c *pageConfig) {
+		c.prefetch = true
+	}
+}
+
+// WithPageRetry 对获取失败的页最多尝试 maxAttempts 次，每次重试前等待的时间从 backoff 开始翻倍；
+// retryable 为 nil 时所有错误都会重试
+func WithPageRetry(maxAttempts int, backoff time.Duration, retryable func(error) bool) PageOption {
+	return func(c *pageConfig) {
+		c.maxAttempts = maxAttempts
+		c.backoff = backoff
+		c.retryable = retryable
+	}
+}
+
+type page[T, C any] struct {
+	items []T
+	next  C
+	done  bool
+	err   error
+	// panicked 为预取 goroutine 中恢复的 panic，在调用方的 goroutine 中重新 panic
+	panicked *goroutinePanic
+}
+
+// Paginate 按需逐页拉取分页接口的数据，第一页使用游标类型的零值；配合 Limit 只会请求实际需要的页
+func Paginate[T, C any](ctx context.Context, fetch PageFetcher[T, C], opts ...PageOption) Stream[T] {
+	cfg := pageConfig{maxAttempts: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return newLazyStream("Paginate", func(s *streamImpl[T]) iterator[T] {
+		ctx, cancel := context.WithCancel(ctx)
+		var pending chan page[T, C]
+		s.onClose(func() error {
+			cancel()
+			if pending != nil {
+				<-pending
+			}
+			return nil
+		})
+
+		var cursor C
+		var buffer []T
+		finished := false
+		return func() (T, bool) {
+			var zero T
+			for len(buffer) == 0 {
+				if finished {
+					return zero, false
+				}
+				var p page[T, C]
+				if pending != nil {
+					p = <-pending
+					pending = nil
+				} else {
+					p = fetchPage(ctx, cfg, fetch, cursor)
+				}
+				if p.panicked != nil {
+					finished = true
+					panic(p.panicked)
+				}
+				if p.err != nil {
+					finished = true
+					s.fail(p.err)
+					return zero, false
+				}
+				buffer, cursor, finished = p.items, p.next, p.done
+				if cfg.prefetch && !finished {
+					pending = make(chan page[T, C], 1)
+					go func(ch chan page[T, C], cursor C) {
+						var p page[T, C]
+						defer func() {
+							if r := recover(); r != nil {
+								p = page[T, C]{panicked: &goroutinePanic{value: r, stack: debug.Stack()}}
+							}
+							ch <- p
+						}()
+						p = fetchPage(ctx, cfg, fetch, cursor)
+					}(pending, cursor)
+				}
+			}
+			item := buffer[0]
+			buffer = buffer[1:]
+			return item, true
+		}
+	})
+}
+
+// fetchPage 获取一页数据，按配置对失败的请求做指数退避重试
+func fetchPage[T, C any](ctx context.Context, cfg pageConfig, fetch PageFetcher[T, C], cursor C) page[T, C] {
+	backoff := cfg.backoff
+	for attempt := 1; ; attempt++ {
+		var p page[T, C]
+		p.items, p.next, p.done, p.err = fetch(ctx, cursor)
+		if p.err == nil || attempt >= cfg.maxAttempts || (cfg.retryable != nil && !cfg.retryable(p.err)) {
+			return p
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return page[T, C]{err: ctx.Err()}
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
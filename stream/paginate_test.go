@@ -0,0 +1,123 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// pagedNumbers 模拟基于偏移量的分页接口，每页 size 个元素，总共 total 个
+func pagedNumbers(total, size int, requests *atomic.Int32) PageFetcher[int, int] {
+	return func(ctx context.Context, offset int) ([]int, int, bool, error) {
+		requests.Add(1)
+		items := make([]int, 0, size)
+		for i := offset; i < offset+size && i < total; i++ {
+			items = append(items, i)
+		}
+		next := offset + len(items)
+		return items, next, next >= total, nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	var requests atomic.Int32
+	count := Paginate(context.Background(), pagedNumbers(25, 10, &requests)).Count()
+
+	if count != 25 {
+		t.Errorf("Expected 25, got %d", count)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("Expected 3 page requests, got %d", requests.Load())
+	}
+}
+
+func TestPaginateWithLimit(t *testing.T) {
+	var requests atomic.Int32
+	result := Paginate(context.Background(), pagedNumbers(1000, 10, &requests)).Limit(15).ToSlice()
+
+	if len(result) != 15 || result[14] != 14 {
+		t.Errorf("Unexpected result: %v", result)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected only 2 page requests, got %d", requests.Load())
+	}
+}
+
+func TestPaginatePrefetch(t *testing.T) {
+	var requests atomic.Int32
+	result := Paginate(context.Background(), pagedNumbers(35, 10, &requests), WithPrefetch()).ToSlice()
+
+	if len(result) != 35 {
+		t.Errorf("Expected 35 elements, got %d", len(result))
+	}
+	for i, v := range result {
+		if v != i {
+			t.Fatalf("Expected %d at index %d, got %d", i, i, v)
+		}
+	}
+
+	requests.Store(0)
+	first := Paginate(context.Background(), pagedNumbers(1000, 10, &requests), WithPrefetch()).FindFirst()
+	if first.Get() != 0 {
+		t.Errorf("Expected 0, got %d", first.Get())
+	}
+	if requests.Load() > 2 {
+		t.Errorf("Expected at most one prefetched page, got %d requests", requests.Load())
+	}
+}
+
+func TestPaginatePrefetchPanic(t *testing.T) {
+	fetch := func(ctx context.Context, offset int) ([]int, int, bool, error) {
+		if offset == 2 {
+			panic("bad cursor")
+		}
+		return []int{offset, offset + 1}, offset + 2, false, nil
+	}
+
+	s := Paginate(context.Background(), fetch, WithPrefetch()).Recover()
+	if result := s.ToSlice(); !equalSlices(result, []int{0, 1}) {
+		t.Errorf("Expected [0 1], got %v", result)
+	}
+	var pe *PanicError
+	if !errors.As(s.Err(), &pe) || pe.Value != "bad cursor" {
+		t.Fatalf("Expected the prefetch panic to be recovered, got %v", s.Err())
+	}
+	if !strings.Contains(string(pe.Stack), "TestPaginatePrefetchPanic.func1") {
+		t.Errorf("Expected the stack of the fetcher, got:\n%s", pe.Stack)
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("Expected the panic to reach the caller without Recover")
+		}
+	}()
+	Paginate(context.Background(), fetch, WithPrefetch()).ToSlice()
+}
+
+func TestPaginateRetry(t *testing.T) {
+	transient := errors.New("503 service unavailable")
+	failures := 2
+	fetch := func(ctx context.Context, page int) ([]string, int, bool, error) {
+		if failures > 0 {
+			failures--
+			return nil, 0, false, transient
+		}
+		return []string{"a", "b"}, page + 1, true, nil
+	}
+
+	result := Paginate(context.Background(), fetch, WithPageRetry(3, time.Millisecond, nil)).ToSlice()
+	if len(result) != 2 {
+		t.Errorf("Expected page after retries, got %v", result)
+	}
+
+	failures = 5
+	s := Paginate(context.Background(), fetch, WithPageRetry(3, time.Millisecond, func(err error) bool {
+		return errors.Is(err, transient)
+	}))
+	if s.Count() != 0 || !errors.Is(s.Err(), transient) {
+		t.Errorf("Expected transient error after exhausting retries, got %v", s.Err())
+	}
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRangeStep(t *testing.T) {
+	if result := RangeStep(0, 10, 3).ToSlice(); !equalSlices(result, []int{0, 3, 6, 9}) {
+		t.Errorf("Expected [0 3 6 9], got %v", result)
+	}
+	if result := RangeStep(10, 0, -4).ToSlice(); !equalSlices(result, []int{10, 6, 2}) {
+		t.Errorf("Expected [10 6 2], got %v", result)
+	}
+	if result := RangeStep[uint8](250, 255, 2).ToSlice(); !equalSlices(result, []uint8{250, 252, 254}) {
+		t.Errorf("Expected [250 252 254], got %v", result)
+	}
+	if result := RangeStep(5, 5, 1).ToSlice(); len(result) != 0 {
+		t.Errorf("Expected empty range, got %v", result)
+	}
+	if result := RangeStep(0, 5, -1).ToSlice(); len(result) != 0 {
+		t.Errorf("Expected empty range for wrong direction, got %v", result)
+	}
+}
+
+func TestRangeStepFloat(t *testing.T) {
+	result := RangeStep(0.0, 1.0, 0.1).ToSlice()
+	if len(result) != 10 {
+		t.Fatalf("Expected 10 values, got %v", result)
+	}
+	if math.Abs(result[9]-0.9) > 1e-9 {
+		t.Errorf("Expected last value 0.9, got %v", result[9])
+	}
+	if result := RangeStep[float32](1, -1, -0.5).ToSlice(); !equalSlices(result, []float32{1, 0.5, 0, -0.5}) {
+		t.Errorf("Expected [1 0.5 0 -0.5], got %v", result)
+	}
+}
+
+func TestRangeStepFloatBoundaries(t *testing.T) {
+	cases := []struct {
+		start, end, step float64
+		closed           bool
+	}{
+		{0, 0.30000000000000004, 0.1, false},
+		{0, 0.6000000000000001, 0.2, false},
+		{0, 1.2000000000000002, 0.1, false},
+		{0, 0.30000000000000004, 0.1, true},
+		{0, 0.3, 0.1, true},
+		{1, 0.7, -0.1, false},
+		{0.7, 1, 0.1, true},
+	}
+	for _, c := range cases {
+		// 逐个计算期望值：与 at 相同地计算 start+i*step，直到越过边界
+		var expected []float64
+		for i := 0; ; i++ {
+			v := c.start + float64(i)*c.step
+			if c.step > 0 && (v > c.end || !c.closed && v == c.end) || c.step < 0 && (v < c.end || !c.closed && v == c.end) {
+				break
+			}
+			expected = append(expected, v)
+		}
+		if count, _ := rangeCount(c.start, c.end, c.step, c.closed); count != int64(len(expected)) {
+			t.Errorf("%+v: expected %d values %v, got count %d", c, len(expected), expected, count)
+		}
+		if c.closed {
+			continue
+		}
+		if result := RangeStep(c.start, c.end, c.step).ToSlice(); !equalSlices(result, expected) {
+			t.Errorf("%+v: expected %v, got %v", c, expected, result)
+		}
+	}
+}
+
+func TestRangeStepZeroPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected panic for zero step")
+		}
+	}()
+	RangeStep(0, 10, 0)
+}
+
+func TestRangeBoundaries(t *testing.T) {
+	if result := RangeClosed(math.MaxInt64-2, math.MaxInt64).ToSlice(); !equalSlices(result, []int64{math.MaxInt64 - 2, math.MaxInt64 - 1, math.MaxInt64}) {
+		t.Errorf("Expected range ending at MaxInt64, got %v", result)
+	}
+	if result := RangeStep[int8](-128, 127, 127).ToSlice(); !equalSlices(result, []int8{-128, -1, 126}) {
+		t.Errorf("Expected [-128 -1 126], got %v", result)
+	}
+	if result := RangeStep[int8](127, -128, -128).ToSlice(); !equalSlices(result, []int8{127, -1}) {
+		t.Errorf("Expected [127 -1], got %v", result)
+	}
+
+	full := RangeClosed(math.MinInt64, math.MaxInt64)
+	if full.Characteristics().Has(Sized) {
+		t.Errorf("Expected range with more than MaxInt64 elements not to be Sized")
+	}
+	if result := full.Skip(1).Limit(2).ToSlice(); !equalSlices(result, []int64{math.MinInt64 + 1, math.MinInt64 + 2}) {
+		t.Errorf("Expected [MinInt64+1 MinInt64+2], got %v", result)
+	}
+}
+
+func TestRangeIsLazy(t *testing.T) {
+	if result := Range(0, 1e18).Skip(1e17).Limit(3).ToSlice(); !equalSlices(result, []int64{1e17, 1e17 + 1, 1e17 + 2}) {
+		t.Errorf("Expected [1e17 1e17+1 1e17+2], got %v", result)
+	}
+	if count := Range(0, 1e18).Skip(10).Count(); count != 1e18-10 {
+		t.Errorf("Expected %d, got %d", int64(1e18-10), count)
+	}
+	if count := RangeStep(int64(0), 1e18, 7).Limit(1e12).Count(); count != 1e12 {
+		t.Errorf("Expected %d, got %d", int64(1e12), count)
+	}
+	if size := RangeStep(0, 100, 7).EstimateSize(); size != 15 {
+		t.Errorf("Expected 15, got %d", size)
+	}
+}
@@ -0,0 +1,198 @@
+package stream
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError 描述流水线中某个阶段发生的 panic
+type PanicError struct {
+	// Stage 为发生 panic 的阶段名称，数据源为数据源名称；经过优化合并的阶段名称形如 "Filter+Map"
+	Stage string
+	// Label 为该阶段通过 Label 设置的标签
+	Label string
+	// Index 为引起 panic 的元素在该阶段输入中的序号（从 0 开始），数据源中为正在产生的元素的序号，
+	// 批量阶段（如 Sorted）无法对应到单个元素，为 -1
+	Index int64
+	// Element 为引起 panic 的元素，无法确定时为 nil
+	Element any
+	// Value 为 recover 得到的值
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	stage := e.Stage
+	if e.Label != "" {
+		stage = fmt.Sprintf("%s %q", e.Stage, e.Label)
+	}
+	if e.Index < 0 {
+		return fmt.Sprintf("stream: panic in %s: %v", stage, e.Value)
+	}
+	return fmt.Sprintf("stream: panic in %s at element %d: %v", stage, e.Index, e.Value)
+}
+
+// Unwrap 在 panic 的值为 error 时返回该 error，便于使用 errors.Is 和 errors.As
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+type panicActionKind int
+
+const (
+	abortOnPanic panicActionKind = iota
+	skipOnPanic
+	fallbackOnPanic
+)
+
+// PanicAction 是 PanicHandler 对一次 panic 的处理决定
+type PanicAction[T any] struct {
+	kind     panicActionKind
+	fallback T
+}
+
+// AbortOnPanic 结束流，之后 Err 返回 *PanicError
+func AbortOnPanic[T any]() PanicAction[T] {
+	return PanicAction[T]{kind: abortOnPanic}
+}
+
+// SkipOnPanic 丢弃引起 panic 的元素，继续处理后续元素
+func SkipOnPanic[T any]() PanicAction[T] {
+	return PanicAction[T]{kind: skipOnPanic}
+}
+
+// FallbackOnPanic 用 value 代替该阶段本应产生的元素
+func FallbackOnPanic[T any](value T) PanicAction[T] {
+	return PanicAction[T]{kind: fallbackOnPanic, fallback: value}
+}
+
+// PanicHandler 决定如何处理中间操作或数据源中的 panic
+type PanicHandler[T any] func(err *PanicError) PanicAction[T]
+
+// Recover 把数据源和全部中间操作中的 panic 转换为错误：流在 panic 处结束，Err 返回 *PanicError
+func (s *streamImpl[T]) Recover() Stream[T] {
+	return s.OnPanic(func(*PanicError) PanicAction[T] {
+		return AbortOnPanic[T]()
+	})
+}
+
+// OnPanic 捕获数据源和全部中间操作中的 panic，并由 handler 决定跳过元素、使用替代值或结束流；
+// 跳过和替代的 panic 计入统计中的错误数，但不影响 Err
+func (s *streamImpl[T]) OnPanic(handler PanicHandler[T]) Stream[T] {
+	s.checkNotConsumed()
+	s.panicHandler = handler
+	return s
+}
+
+// lastPulled 记录阶段最近一次从上游拉取的元素及其序号
+type lastPulled[T any] struct {
+	index int64
+	item  T
+}
+
+// recoverPanics 为数据源和每个中间操作加上 panic 恢复
+func (s *streamImpl[T]) recoverPanics(origin stageInfo, source iterator[T], ops []stage[T]) (iterator[T], []stage[T]) {
+	source = s.guard(origin, source, nil)
+	guarded := make([]stage[T], len(ops))
+	for i, op := range ops {
+		apply := op.apply
+		info := op.stageInfo
+		guarded[i] = op
+		guarded[i].apply = func(upstream iterator[T]) iterator[T] {
+			if info.barrier {
+				return s.guard(info, apply(upstream), &lastPulled[T]{index: -1})
+			}
+			last := &lastPulled[T]{index: -1}
+			return s.guard(info, apply(func() (T, bool) {
+				item, ok := upstream()
+				if ok {
+					last.index++
+					last.item = item
+				}
+				return item, ok
+			}), last)
+		}
+	}
+	return source, guarded
+}
+
+// guard 拉取 it 时恢复其中的 panic；last 为 nil 表示数据源，批量阶段的 last 始终没有元素，其中的 panic 总是结束流；
+// 无法继续的数据源中的 panic 同样总是结束流
+func (s *streamImpl[T]) guard(info stageInfo, it iterator[T], last *lastPulled[T]) iterator[T] {
+	var produced int64
+	done := false
+	return func() (T, bool) {
+		var zero T
+		for !done {
+			item, ok, err := tryPull(it)
+			if err == nil {
+				if ok {
+					produced++
+				}
+				return item, ok
+			}
+
+			err.Stage, err.Label, err.Index = info.name, info.label, produced
+			if last != nil {
+				err.Index = last.index
+				if last.index >= 0 {
+					err.Element = last.item
+				}
+			}
+			action := AbortOnPanic[T]()
+			if err.Index >= 0 {
+				action = s.panicHandler(err)
+			}
+			// 惰性数据源在 panic 后不一定前进，再次拉取可能重复同一个 panic，因此只能结束流
+			if last == nil && !s.resumable() {
+				action = AbortOnPanic[T]()
+			}
+			switch action.kind {
+			case skipOnPanic:
+				s.errCount++
+				if last == nil {
+					produced++
+				}
+			case fallbackOnPanic:
+				s.errCount++
+				produced++
+				return action.fallback, true
+			default:
+				done = true
+				s.fail(err)
+			}
+		}
+		return zero, false
+	}
+}
+
+// resumable 报告数据源在 panic 后再次拉取时是否从下一个元素继续：按下标取值的数据源总是先前进再取值，
+// 惰性数据源需要通过 resumesAfterPanic 声明
+func (s *streamImpl[T]) resumable() bool {
+	return s.open == nil || s.resumesAfterPanic
+}
+
+func tryPull[T any](it iterator[T]) (item T, ok bool, err *PanicError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+			if p, ok := r.(*goroutinePanic); ok {
+				err.Value, err.Stack = p.value, p.stack
+			}
+		}
+	}()
+	item, ok = it()
+	return item, ok, nil
+}
+
+// goroutinePanic 携带在其它 goroutine 中恢复的 panic 值和当时的调用栈，
+// 在调用方的 goroutine 中重新 panic 时使用，使 PanicError.Stack 指向真正发生 panic 的位置
+type goroutinePanic struct {
+	value any
+	stack []byte
+}
+
+func (p *goroutinePanic) Error() string {
+	return fmt.Sprintf("%v\n\ngoroutine stack:\n%s", p.value, p.stack)
+}
@@ -0,0 +1,145 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRecoverAborts(t *testing.T) {
+	s := Of(1, 2, 0, 4).Map(func(n int) int { return 12 / n }).Label("divide").Recover()
+	result := s.ToSlice()
+	if !equalSlices(result, []int{12, 6}) {
+		t.Errorf("Expected [12 6], got %v", result)
+	}
+
+	var pe *PanicError
+	if !errors.As(s.Err(), &pe) {
+		t.Fatalf("Expected *PanicError, got %v", s.Err())
+	}
+	if pe.Stage != "Map" || pe.Label != "divide" || pe.Index != 2 || pe.Element != 0 {
+		t.Errorf("Unexpected panic context: %+v", pe)
+	}
+	if !strings.Contains(pe.Error(), `Map "divide" at element 2`) || len(pe.Stack) == 0 {
+		t.Errorf("Unexpected error: %v", pe)
+	}
+}
+
+func TestOnPanicSkipAndFallback(t *testing.T) {
+	var seen []int64
+	skip := Of("a", "", "c").
+		Filter(func(s string) bool { return s[0] != 'b' }).
+		OnPanic(func(err *PanicError) PanicAction[string] {
+			seen = append(seen, err.Index)
+			return SkipOnPanic[string]()
+		})
+	if result := skip.ToSlice(); !equalSlices(result, []string{"a", "c"}) {
+		t.Errorf("Expected [a c], got %v", result)
+	}
+	if skip.Err() != nil || !equalSlices(seen, []int64{1}) {
+		t.Errorf("Expected one skipped element at index 1 and no error, got %v, %v", seen, skip.Err())
+	}
+
+	fallback := Of(2, 0, 5).
+		Map(func(n int) int { return 10 / n }).
+		OnPanic(func(*PanicError) PanicAction[int] { return FallbackOnPanic(-1) })
+	if result := fallback.ToSlice(); !equalSlices(result, []int{5, -1, 2}) {
+		t.Errorf("Expected [5 -1 2], got %v", result)
+	}
+}
+
+func TestRecoverCountRunsStages(t *testing.T) {
+	panicky := func(n int) int {
+		if n == 2 {
+			panic("bad element")
+		}
+		return n
+	}
+
+	skipped := Of(1, 2, 3).OnPanic(func(*PanicError) PanicAction[int] { return SkipOnPanic[int]() }).Map(panicky)
+	if count := skipped.Count(); count != 2 || skipped.Err() != nil {
+		t.Errorf("Expected count 2 without error, got %d (%v)", count, skipped.Err())
+	}
+
+	recovered := Of(1, 2, 3).Recover().Map(panicky)
+	var pe *PanicError
+	if count := recovered.Count(); count != 1 || !errors.As(recovered.Err(), &pe) {
+		t.Errorf("Expected count 1 with *PanicError, got %d (%v)", count, recovered.Err())
+	}
+}
+
+func TestRecoverSourceAndUnwrap(t *testing.T) {
+	i := 0
+	s := Generate(func() int {
+		i++
+		if i == 3 {
+			panic(io.ErrUnexpectedEOF)
+		}
+		return i
+	}, 5).Recover()
+	if result := s.ToSlice(); !equalSlices(result, []int{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", result)
+	}
+	if !errors.Is(s.Err(), io.ErrUnexpectedEOF) {
+		t.Errorf("Expected wrapped io.ErrUnexpectedEOF, got %v", s.Err())
+	}
+
+	indexed := RangeStep(0, 4, 1).Map(func(n int) int {
+		if n == 1 {
+			panic("bad")
+		}
+		return n
+	}).OnPanic(func(*PanicError) PanicAction[int] { return SkipOnPanic[int]() })
+	if result := indexed.ToSlice(); !equalSlices(result, []int{0, 2, 3}) {
+		t.Errorf("Expected [0 2 3], got %v", result)
+	}
+}
+
+func TestRecoverBarrierAlwaysAborts(t *testing.T) {
+	called := false
+	s := Of(3, 1, 2).
+		Sorted(func(a, b int) int { panic("broken comparator") }).
+		OnPanic(func(*PanicError) PanicAction[int] {
+			called = true
+			return SkipOnPanic[int]()
+		})
+	if result := s.ToSlice(); len(result) != 0 {
+		t.Errorf("Expected empty result, got %v", result)
+	}
+	var pe *PanicError
+	if called || !errors.As(s.Err(), &pe) || pe.Index != -1 || pe.Element != nil {
+		t.Errorf("Expected abort without calling handler, got %v", s.Err())
+	}
+}
+
+func TestRecoverCountsErrorsInStats(t *testing.T) {
+	s := Of(1, 0, 0, 2).Unoptimized().
+		Map(func(n int) int { return 2 / n }).
+		OnPanic(func(*PanicError) PanicAction[int] { return SkipOnPanic[int]() }).
+		Observe(nil)
+	s.ToSlice()
+	if errs := s.Stats().Stages[1].Errors; errs != 2 {
+		t.Errorf("Expected 2 errors on Map, got %d", errs)
+	}
+}
+
+func TestSkipInLazySourceAborts(t *testing.T) {
+	fetches := 0
+	s := Paginate(context.Background(), func(ctx context.Context, page int) ([]int, int, bool, error) {
+		fetches++
+		if page == 1 {
+			panic("broken page")
+		}
+		return []int{page}, page + 1, false, nil
+	}).OnPanic(func(*PanicError) PanicAction[int] { return SkipOnPanic[int]() })
+
+	if result := s.ToSlice(); !equalSlices(result, []int{0}) {
+		t.Errorf("Expected [0], got %v", result)
+	}
+	var pe *PanicError
+	if !errors.As(s.Err(), &pe) || pe.Stage != "Paginate" || fetches != 2 {
+		t.Errorf("Expected *PanicError from Paginate after 2 fetches, got %v after %d fetches", s.Err(), fetches)
+	}
+}
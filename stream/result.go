@@ -0,0 +1,167 @@
+package stream
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Result 保存一个值或者一个错误，用于在流中传递每个元素各自的处理结果，而不是在第一个错误时中断
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+func OkResult[T any](value T) Result[T] {
+	return Result[T]{value: value}
+}
+
+// ErrResult 创建失败的 Result，err 为 nil 时 panic
+func ErrResult[T any](err error) Result[T] {
+	if err == nil {
+		panic("stream: ErrResult called with nil error")
+	}
+	return Result[T]{err: err}
+}
+
+// OfResult 把 (value, err) 返回值转换为 Result
+func OfResult[T any](value T, err error) Result[T] {
+	if err != nil {
+		return Result[T]{err: err}
+	}
+	return OkResult(value)
+}
+
+// MapResult 在成功时用 mapper 转换其中的值，失败时原样传递错误
+func MapResult[T, R any](r Result[T], mapper Function[T, R]) Result[R] {
+	if r.err != nil {
+		return Result[R]{err: r.err}
+	}
+	return OkResult(mapper(r.value))
+}
+
+// FlatMapResult 在成功时返回 mapper 的结果，失败时原样传递错误
+func FlatMapResult[T, R any](r Result[T], mapper Function[T, Result[R]]) Result[R] {
+	if r.err != nil {
+		return Result[R]{err: r.err}
+	}
+	return mapper(r.value)
+}
+
+// MapToResult 用可能失败的 mapper 映射每个元素，错误作为元素保留在流中
+func MapToResult[T, R any](s Stream[T], mapper func(T) (R, error)) Stream[Result[R]] {
+	return derive("MapToResult", s, func(_ *streamImpl[Result[R]], upstream iterator[T]) iterator[Result[R]] {
+		return func() (Result[R], bool) {
+			item, ok := upstream()
+			if !ok {
+				return Result[R]{}, false
+			}
+			return OfResult(mapper(item)), true
+		}
+	})
+}
+
+func (r Result[T]) IsOk() bool {
+	return r.err == nil
+}
+
+func (r Result[T]) IsErr() bool {
+	return r.err != nil
+}
+
+func (r Result[T]) Err() error {
+	return r.err
+}
+
+// Unwrap 以 Go 惯用的 (value, err) 形式返回结果
+func (r Result[T]) Unwrap() (T, error) {
+	return r.value, r.err
+}
+
+func (r Result[T]) OrElse(other T) T {
+	if r.err != nil {
+		return other
+	}
+	return r.value
+}
+
+// ToOptional 成功时返回包含该值的 Optional，失败时返回空的 Optional
+func (r Result[T]) ToOptional() Optional[T] {
+	return OptionalFromErr(r.value, r.err)
+}
+
+func (r Result[T]) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("Err(%v)", r.err)
+	}
+	return fmt.Sprintf("Ok(%v)", r.value)
+}
+
+// ResultPartition 是 PartitionResults 的结果，Values 和 Errors 各自保持在流中的顺序
+type ResultPartition[T any] struct {
+	Values []T
+	Errors []error
+}
+
+type partitionResultsCollector[T any] struct{}
+
+func (c partitionResultsCollector[T]) Collect(items []Result[T]) any {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c partitionResultsCollector[T]) collectFrom(it iterator[Result[T]], sizeHint int) any {
+	partition := ResultPartition[T]{Values: make([]T, 0, sizeHint)}
+	for item, ok := it(); ok; item, ok = it() {
+		if item.err != nil {
+			partition.Errors = append(partition.Errors, item.err)
+		} else {
+			partition.Values = append(partition.Values, item.value)
+		}
+	}
+	return partition
+}
+
+// PartitionResults 把成功的值和错误分开收集
+func PartitionResults[T any]() Collector[Result[T], any, ResultPartition[T]] {
+	return partitionResultsCollector[T]{}
+}
+
+type allErrorsCollector[T any] struct{}
+
+func (c allErrorsCollector[T]) Collect(items []Result[T]) any {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c allErrorsCollector[T]) collectFrom(it iterator[Result[T]], sizeHint int) any {
+	var errs []error
+	for item, ok := it(); ok; item, ok = it() {
+		if item.err != nil {
+			errs = append(errs, item.err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CollectAllErrors 消费全部元素，返回所有错误的 errors.Join，没有错误时返回 nil
+func CollectAllErrors[T any]() Collector[Result[T], any, error] {
+	return allErrorsCollector[T]{}
+}
+
+type firstErrorCollector[T any] struct{}
+
+func (c firstErrorCollector[T]) Collect(items []Result[T]) any {
+	return c.collectFrom(sliceIterator(items), len(items))
+}
+
+func (c firstErrorCollector[T]) collectFrom(it iterator[Result[T]], sizeHint int) any {
+	for item, ok := it(); ok; item, ok = it() {
+		if item.err != nil {
+			return item.err
+		}
+	}
+	return nil
+}
+
+// FirstError 返回第一个错误，遇到错误后不再拉取后续元素；没有错误时返回 nil
+func FirstError[T any]() Collector[Result[T], any, error] {
+	return firstErrorCollector[T]{}
+}
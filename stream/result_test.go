@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestResult(t *testing.T) {
+	ok := OfResult(strconv.Atoi("42"))
+	if !ok.IsOk() || ok.OrElse(0) != 42 {
+		t.Errorf("Expected Ok(42), got %v", ok)
+	}
+	failed := OfResult(strconv.Atoi("x"))
+	if !failed.IsErr() || failed.OrElse(-1) != -1 || failed.ToOptional().IsPresent() {
+		t.Errorf("Expected error result, got %v", failed)
+	}
+
+	doubled := MapResult(ok, func(n int) string { return strconv.Itoa(n * 2) })
+	if v, err := doubled.Unwrap(); err != nil || v != "84" {
+		t.Errorf("Expected 84, got %q, %v", v, err)
+	}
+	if v, err := MapResult(failed, strconv.Itoa).Unwrap(); err != failed.Err() || v != "" {
+		t.Errorf("Expected original error to be carried, got %q, %v", v, err)
+	}
+
+	parse := func(s string) Result[int] { return OfResult(strconv.Atoi(s)) }
+	if r := FlatMapResult(OkResult("7"), parse); r.OrElse(0) != 7 {
+		t.Errorf("Expected 7, got %v", r)
+	}
+	if r := FlatMapResult(OkResult("y"), parse); r.IsOk() {
+		t.Errorf("Expected error, got %v", r)
+	}
+
+	if s := ErrResult[int](errors.New("boom")).String(); s != "Err(boom)" {
+		t.Errorf("Expected Err(boom), got %s", s)
+	}
+	if s := OkResult(1).String(); s != "Ok(1)" {
+		t.Errorf("Expected Ok(1), got %s", s)
+	}
+}
+
+func TestPartitionResults(t *testing.T) {
+	results := MapToResult(Of("1", "a", "3", "b"), strconv.Atoi)
+	partition := results.Collect(PartitionResults[int]()).(ResultPartition[int])
+	if !equalSlices(partition.Values, []int{1, 3}) {
+		t.Errorf("Expected [1 3], got %v", partition.Values)
+	}
+	if len(partition.Errors) != 2 {
+		t.Errorf("Expected 2 errors, got %v", partition.Errors)
+	}
+}
+
+func TestCollectAllErrors(t *testing.T) {
+	validate := func(n int) (int, error) {
+		if n < 0 {
+			return 0, errors.New("negative: " + strconv.Itoa(n))
+		}
+		return n, nil
+	}
+
+	err, _ := MapToResult(Of(1, -2, 3, -4), validate).Collect(CollectAllErrors[int]()).(error)
+	if err == nil || err.Error() != "negative: -2\nnegative: -4" {
+		t.Errorf("Expected both failures, got %v", err)
+	}
+
+	err, _ = MapToResult(Of(1, 2), validate).Collect(CollectAllErrors[int]()).(error)
+	if err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
+
+func TestFirstError(t *testing.T) {
+	var visited int
+	mapper := func(s string) (int, error) {
+		visited++
+		return strconv.Atoi(s)
+	}
+	err, _ := MapToResult(Of("1", "x", "2", "y"), mapper).Collect(FirstError[int]()).(error)
+	if err == nil || visited != 2 {
+		t.Errorf("Expected first error after 2 elements, got %v after %d", err, visited)
+	}
+
+	err, _ = MapToResult(Of("1"), mapper).Collect(FirstError[int]()).(error)
+	if err != nil {
+		t.Errorf("Expected nil, got %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 表示熔断器处于打开状态，调用没有被执行
+var ErrCircuitOpen = errors.New("stream: circuit breaker is open")
+
+// RetryPolicy 描述 MapWithRetry 对每个元素的重试方式，零值表示只尝试一次、不设超时
+type RetryPolicy struct {
+	// MaxAttempts 为每个元素最多尝试的次数（包括第一次），小于 1 时视为 1
+	MaxAttempts int
+	// InitialBackoff 为第一次重试前的等待时间，之后每次乘以 Multiplier，但不超过 MaxBackoff（为 0 时不限制）
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Multiplier 小于等于 0 时视为 2
+	Multiplier float64
+	// Jitter 为 0 到 1 之间的比例，每次等待的时间在 [backoff*(1-Jitter), backoff] 中随机选取
+	Jitter float64
+	// AttemptTimeout 限制每次尝试的时间，ElementTimeout 限制每个元素全部尝试和等待的时间，为 0 时不限制
+	AttemptTimeout time.Duration
+	ElementTimeout time.Duration
+	// RetryBudget 为整条流最多重试的次数（不包括第一次尝试），为 0 时不限制
+	RetryBudget int
+	// Retryable 判断错误是否值得重试，为 nil 时所有错误都会重试
+	Retryable func(error) bool
+	// Breaker 为可选的熔断器，可以在多条流之间共享
+	Breaker *CircuitBreaker
+	// Clock 为 nil 时使用 SystemClock
+	Clock Clock
+	// Source 为计算 Jitter 的随机数源，为 nil 时使用以当前时间为种子的随机数源
+	Source rand.Source
+}
+
+func (p RetryPolicy) normalized() RetryPolicy {
+	p.MaxAttempts = max(p.MaxAttempts, 1)
+	if p.Multiplier <= 0 {
+		p.Multiplier = 2
+	}
+	p.Jitter = min(max(p.Jitter, 0), 1)
+	if p.Clock == nil {
+		p.Clock = SystemClock()
+	}
+	if p.Source == nil {
+		p.Source = rand.NewSource(time.Now().UnixNano())
+	}
+	return p
+}
+
+// retrier 保存一条流中所有元素共享的重试状态
+type retrier struct {
+	RetryPolicy
+	random  *rand.Rand
+	retries int
+}
+
+// MapWithRetry 用可能失败的 fn 映射每个元素，失败时按 policy 重试；
+// 重试后仍然失败的元素以错误的 Result 保留在流中，ctx 结束时流随之结束，Err 返回 ctx.Err()
+func MapWithRetry[T, R any](ctx context.Context, s Stream[T], fn func(context.Context, T) (R, error), policy RetryPolicy) Stream[Result[R]] {
+	policy = policy.normalized()
+	return derive("MapWithRetry", s, func(out *streamImpl[Result[R]], upstream iterator[T]) iterator[Result[R]] {
+		r := &retrier{RetryPolicy: policy, random: rand.New(policy.Source)}
+		return func() (Result[R], bool) {
+			if err := ctx.Err(); err != nil {
+				out.fail(err)
+				return Result[R]{}, false
+			}
+			item, ok := upstream()
+			if !ok {
+				return Result[R]{}, false
+			}
+			value, err := callWithRetry(ctx, r, item, fn)
+			if ctx.Err() != nil {
+				out.fail(ctx.Err())
+				return Result[R]{}, false
+			}
+			return OfResult(value, err), true
+		}
+	})
+}
+
+func callWithRetry[T, R any](ctx context.Context, r *retrier, item T, fn func(context.Context, T) (R, error)) (R, error) {
+	var zero R
+	if r.ElementTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = withClockTimeout(ctx, r.Clock, r.ElementTimeout,
+			fmt.Errorf("stream: element timed out after %s: %w", r.ElementTimeout, context.DeadlineExceeded))
+		defer cancel()
+	}
+
+	backoff := r.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		if r.Breaker != nil {
+			if err := r.Breaker.allow(); err != nil {
+				return zero, err
+			}
+		}
+		value, err := attemptCall(ctx, r, item, fn)
+		if r.Breaker != nil {
+			r.Breaker.record(err)
+		}
+		if err == nil {
+			return value, nil
+		}
+		if ctx.Err() != nil {
+			return zero, context.Cause(ctx)
+		}
+		if attempt >= r.MaxAttempts || (r.RetryBudget > 0 && r.retries >= r.RetryBudget) ||
+			(r.Retryable != nil && !r.Retryable(err)) {
+			return zero, err
+		}
+		r.retries++
+		if r.Clock.Sleep(ctx, r.jittered(backoff)) != nil {
+			return zero, context.Cause(ctx)
+		}
+		backoff = time.Duration(float64(backoff) * r.Multiplier)
+		if r.MaxBackoff > 0 {
+			backoff = min(backoff, r.MaxBackoff)
+		}
+	}
+}
+
+// attemptCall 执行一次尝试，超时时返回超时错误而不是 fn 返回的错误
+func attemptCall[T, R any](ctx context.Context, r *retrier, item T, fn func(context.Context, T) (R, error)) (R, error) {
+	if r.AttemptTimeout <= 0 {
+		return fn(ctx, item)
+	}
+	attemptCtx, cancel := withClockTimeout(ctx, r.Clock, r.AttemptTimeout,
+		fmt.Errorf("stream: attempt timed out after %s: %w", r.AttemptTimeout, context.DeadlineExceeded))
+	defer cancel()
+	value, err := fn(attemptCtx, item)
+	if err != nil && ctx.Err() == nil && attemptCtx.Err() != nil {
+		err = context.Cause(attemptCtx)
+	}
+	return value, err
+}
+
+func (r *retrier) jittered(backoff time.Duration) time.Duration {
+	if r.Jitter == 0 {
+		return backoff
+	}
+	return time.Duration(float64(backoff) * (1 - r.Jitter*r.random.Float64()))
+}
+
+// CircuitBreaker 在连续失败达到阈值后打开，打开期间的调用直接以 ErrCircuitOpen 失败；
+// 经过 cooldown 后允许一次试探调用，成功则关闭，失败则重新打开；cooldown 为 0 时打开后不再关闭。
+// 可以在多条流和多个 goroutine 之间共享
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     Clock
+
+	mu       sync.Mutex
+	failures int
+	open     bool
+	probing  bool
+	openedAt time.Time
+}
+
+// NewCircuitBreaker 创建在连续 threshold 次调用失败后打开的熔断器，clock 为 nil 时使用 SystemClock
+func NewCircuitBreaker(threshold int, cooldown time.Duration, clock Clock) *CircuitBreaker {
+	if clock == nil {
+		clock = SystemClock()
+	}
+	return &CircuitBreaker{threshold: max(threshold, 1), cooldown: cooldown, clock: clock}
+}
+
+func (b *CircuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
+
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.open {
+		return nil
+	}
+	if b.cooldown > 0 && !b.probing && b.clock.Now().Sub(b.openedAt) >= b.cooldown {
+		b.probing = true
+		return nil
+	}
+	return ErrCircuitOpen
+}
+
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil {
+		b.failures, b.open, b.probing = 0, false, false
+		return
+	}
+	b.failures++
+	if b.probing || b.failures >= b.threshold {
+		b.open, b.probing, b.openedAt = true, false, b.clock.Now()
+	}
+}
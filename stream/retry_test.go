@@ -0,0 +1,171 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("503 service unavailable")
+
+// flakyCall 对每个元素先失败 failures[item] 次再成功
+func flakyCall(failures map[string]int, calls *int) func(context.Context, string) (string, error) {
+	return func(ctx context.Context, item string) (string, error) {
+		*calls++
+		if failures[item] > 0 {
+			failures[item]--
+			return "", errUnavailable
+		}
+		return strings.ToUpper(item), nil
+	}
+}
+
+func TestMapWithRetryBackoff(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	start := clock.Now()
+	var calls int
+	policy := RetryPolicy{MaxAttempts: 4, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Clock: clock}
+
+	results := MapWithRetry(context.Background(), Of("a", "b", "c"),
+		flakyCall(map[string]int{"a": 3, "c": 5}, &calls), policy).ToSlice()
+
+	if v, err := results[0].Unwrap(); err != nil || v != "A" {
+		t.Errorf("Expected A after retries, got %v", results[0])
+	}
+	if results[1].OrElse("") != "B" {
+		t.Errorf("Expected B, got %v", results[1])
+	}
+	if !errors.Is(results[2].Err(), errUnavailable) {
+		t.Errorf("Expected c to fail after 4 attempts, got %v", results[2])
+	}
+	if calls != 9 {
+		t.Errorf("Expected 9 calls, got %d", calls)
+	}
+	// 每个失败的元素等待 1s + 2s + 3s（受 MaxBackoff 限制）
+	if elapsed := clock.Now().Sub(start); elapsed != 12*time.Second {
+		t.Errorf("Expected 12s of virtual time, got %s", elapsed)
+	}
+}
+
+func TestMapWithRetryJitterAndBudget(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	var calls int
+	policy := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		Jitter:         0.5,
+		RetryBudget:    3,
+		Clock:          clock,
+		Source:         rand.NewSource(1),
+	}
+	results := MapWithRetry(context.Background(), Of("a", "b", "c"),
+		flakyCall(map[string]int{"a": 5, "b": 5, "c": 5}, &calls), policy).ToSlice()
+
+	// a 用掉 2 次重试，b 用掉剩下的 1 次，c 只尝试一次
+	if calls != 6 {
+		t.Errorf("Expected 6 calls within the retry budget, got %d", calls)
+	}
+	for _, r := range results {
+		if r.IsOk() {
+			t.Errorf("Expected all elements to fail, got %v", r)
+		}
+	}
+	if elapsed := clock.Now().Sub(time.Unix(0, 0)); elapsed < 2*time.Second || elapsed > 4*time.Second {
+		t.Errorf("Expected jittered backoff between 2s and 4s, got %s", elapsed)
+	}
+
+	calls = 0
+	policy = RetryPolicy{MaxAttempts: 3, Clock: clock, Retryable: func(err error) bool { return false }}
+	MapWithRetry(context.Background(), Of("a"), flakyCall(map[string]int{"a": 1}, &calls), policy).ToSlice()
+	if calls != 1 {
+		t.Errorf("Expected non-retryable error not to be retried, got %d calls", calls)
+	}
+}
+
+func TestMapWithRetryTimeouts(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	slow := func(ctx context.Context, d time.Duration) (time.Duration, error) {
+		if err := clock.Sleep(ctx, d); err != nil {
+			return 0, err
+		}
+		return d, nil
+	}
+
+	policy := RetryPolicy{MaxAttempts: 2, AttemptTimeout: time.Second, Clock: clock}
+	results := MapWithRetry(context.Background(), Of(500*time.Millisecond, 5*time.Second), slow, policy).ToSlice()
+	if results[0].OrElse(0) != 500*time.Millisecond {
+		t.Errorf("Expected fast call to succeed, got %v", results[0])
+	}
+	if !errors.Is(results[1].Err(), context.DeadlineExceeded) || !strings.Contains(results[1].Err().Error(), "attempt timed out") {
+		t.Errorf("Expected attempt timeout, got %v", results[1])
+	}
+
+	start := clock.Now()
+	policy = RetryPolicy{MaxAttempts: 10, InitialBackoff: time.Second, ElementTimeout: 5 * time.Second, Clock: clock}
+	failing := func(ctx context.Context, n int) (int, error) { return 0, errUnavailable }
+	result := MapWithRetry(context.Background(), Of(1), failing, policy).ToSlice()[0]
+	if !errors.Is(result.Err(), context.DeadlineExceeded) {
+		t.Errorf("Expected element timeout, got %v", result)
+	}
+	if elapsed := clock.Now().Sub(start); elapsed != 5*time.Second {
+		t.Errorf("Expected to stop at the element deadline, got %s", elapsed)
+	}
+}
+
+func TestMapWithRetryCircuitBreaker(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	breaker := NewCircuitBreaker(3, time.Minute, clock)
+	var calls int
+	failures := map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "e": 0}
+	policy := RetryPolicy{Breaker: breaker, Clock: clock}
+
+	results := MapWithRetry(context.Background(), Of("a", "b", "c", "d", "e"), flakyCall(failures, &calls), policy).ToSlice()
+	if calls != 3 || !breaker.IsOpen() {
+		t.Errorf("Expected breaker to open after 3 calls, got %d calls", calls)
+	}
+	if !errors.Is(results[3].Err(), ErrCircuitOpen) || !errors.Is(results[4].Err(), ErrCircuitOpen) {
+		t.Errorf("Expected remaining elements to fail fast, got %v", results[3:])
+	}
+
+	clock.Advance(time.Minute)
+	results = MapWithRetry(context.Background(), Of("e", "f"), flakyCall(failures, &calls), policy).ToSlice()
+	if results[0].OrElse("") != "E" || results[1].OrElse("") != "F" || breaker.IsOpen() {
+		t.Errorf("Expected breaker to close after a successful probe, got %v", results)
+	}
+}
+
+func TestMapWithRetryContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := MapWithRetry(ctx, Of(1, 2, 3), func(_ context.Context, n int) (int, error) {
+		if n == 2 {
+			cancel()
+		}
+		return n, nil
+	}, RetryPolicy{})
+	if result := s.ToSlice(); len(result) != 1 || !errors.Is(s.Err(), context.Canceled) {
+		t.Errorf("Expected stream to end on cancellation, got %v, %v", result, s.Err())
+	}
+}
+
+func TestVirtualClock(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	var fired []int
+	clock.AfterFunc(2*time.Second, func() { fired = append(fired, 2) })
+	clock.AfterFunc(time.Second, func() { fired = append(fired, 1) })
+	stop := clock.AfterFunc(3*time.Second, func() { fired = append(fired, 3) })
+
+	clock.Advance(2 * time.Second)
+	if !equalSlices(fired, []int{1, 2}) {
+		t.Errorf("Expected [1 2], got %v", fired)
+	}
+	if !stop() || stop() {
+		t.Errorf("Expected pending timer to be stopped exactly once")
+	}
+	clock.Advance(time.Hour)
+	if len(fired) != 2 || clock.Now() != time.Unix(0, 0).Add(time.Hour+2*time.Second) {
+		t.Errorf("Unexpected state: %v at %v", fired, clock.Now())
+	}
+}
@@ -0,0 +1,153 @@
+package stream
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// indexed 记录元素在流中的原始位置，抽样结果按原始顺序输出
+type indexed[T any] struct {
+	index int
+	item  T
+}
+
+func newRand(source rand.Source) *rand.Rand {
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+	return rand.New(source)
+}
+
+func (s *streamImpl[T]) Shuffle(source rand.Source) Stream[T] {
+	return s.apply("Shuffle", func(items []T) []T {
+		rng := newRand(source)
+		result := make([]T, len(items))
+		copy(result, items)
+		rng.Shuffle(len(result), func(i, j int) {
+			result[i], result[j] = result[j], result[i]
+		})
+		return result
+	})
+}
+
+// Sample 使用蓄水池抽样，只在内存中保留 n 个元素，适用于长度未知的流
+func (s *streamImpl[T]) Sample(n int, source rand.Source) Stream[T] {
+	return s.applyStreaming("Sample", func(it iterator[T]) []T {
+		return reservoirSample(it, n, newRand(source))
+	})
+}
+
+// SampleFraction 以概率 p 独立地保留每个元素，逐个元素处理，不需要收集上游
+func (s *streamImpl[T]) SampleFraction(p float64, source rand.Source) Stream[T] {
+	return s.pipe("SampleFraction", func(upstream iterator[T]) iterator[T] {
+		rng := newRand(source)
+		return func() (T, bool) {
+			for item, ok := upstream(); ok; item, ok = upstream() {
+				if rng.Float64() < p {
+					return item, true
+				}
+			}
+			var zero T
+			return zero, false
+		}
+	})
+}
+
+// WeightedSample 使用 Efraimidis-Spirakis A-Res 算法做不放回加权抽样，权重 <= 0 的元素不会被选中；
+// 只在内存中保留大小为 n 的堆
+func (s *streamImpl[T]) WeightedSample(n int, weight Function[T, float64], source rand.Source) Stream[T] {
+	return s.applyStreaming("WeightedSample", func(it iterator[T]) []T {
+		if n <= 0 {
+			return []T{}
+		}
+		type keyed struct {
+			key float64
+			indexed[T]
+		}
+		rng := newRand(source)
+		h := newBoundedHeap(n, func(a, b keyed) int {
+			if a.key < b.key {
+				return -1
+			} else if a.key > b.key {
+				return 1
+			}
+			return 0
+		})
+		i := 0
+		for item, ok := it(); ok; item, ok = it() {
+			if w := weight(item); w > 0 && !math.IsNaN(w) {
+				h.offer(keyed{key: math.Pow(rng.Float64(), 1/w), indexed: indexed[T]{index: i, item: item}})
+			}
+			i++
+		}
+		selected := make([]indexed[T], len(h.items))
+		for i, k := range h.items {
+			selected[i] = k.indexed
+		}
+		return inEncounterOrder(selected)
+	})
+}
+
+// StratifiedSample 按 key 分层，每层独立做蓄水池抽样，最多保留 nPerStratum 个元素；
+// 只在内存中保留每层的蓄水池
+func StratifiedSample[T any, K comparable](s Stream[T], key Function[T, K], nPerStratum int, source rand.Source) Stream[T] {
+	return s.applyStreaming("StratifiedSample", func(it iterator[T]) []T {
+		if nPerStratum <= 0 {
+			return []T{}
+		}
+		rng := newRand(source)
+		reservoirs := make(map[K][]indexed[T])
+		seen := make(map[K]int)
+		i := 0
+		for item, ok := it(); ok; item, ok = it() {
+			k := key(item)
+			seen[k]++
+			reservoirs[k] = offerReservoir(reservoirs[k], indexed[T]{index: i, item: item}, seen[k], nPerStratum, rng)
+			i++
+		}
+		selected := make([]indexed[T], 0)
+		for _, r := range reservoirs {
+			selected = append(selected, r...)
+		}
+		return inEncounterOrder(selected)
+	})
+}
+
+// reservoirSample 使用 Algorithm R 从未知长度的序列中等概率抽取 n 个元素
+func reservoirSample[T any](it iterator[T], n int, rng *rand.Rand) []T {
+	if n <= 0 {
+		return []T{}
+	}
+	reservoir := make([]indexed[T], 0, min(n, 1024))
+	i := 0
+	for item, ok := it(); ok; item, ok = it() {
+		reservoir = offerReservoir(reservoir, indexed[T]{index: i, item: item}, i+1, n, rng)
+		i++
+	}
+	return inEncounterOrder(reservoir)
+}
+
+func offerReservoir[T any](reservoir []indexed[T], item indexed[T], seen, n int, rng *rand.Rand) []indexed[T] {
+	if n <= 0 {
+		return reservoir
+	}
+	if len(reservoir) < n {
+		return append(reservoir, item)
+	}
+	if j := rng.Intn(seen); j < n {
+		reservoir[j] = item
+	}
+	return reservoir
+}
+
+func inEncounterOrder[T any](selected []indexed[T]) []T {
+	sortSlice(selected, func(a, b indexed[T]) int {
+		return a.index - b.index
+	})
+	result := make([]T, len(selected))
+	for i, s := range selected {
+		result[i] = s.item
+	}
+	return result
+}
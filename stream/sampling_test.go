@@ -0,0 +1,163 @@
+package stream
+
+import (
+	"math/rand"
+	"runtime"
+	"testing"
+)
+
+func equalSlices[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestShuffle(t *testing.T) {
+	first := Range(0, 20).Shuffle(rand.NewSource(42)).ToSlice()
+	second := Range(0, 20).Shuffle(rand.NewSource(42)).ToSlice()
+
+	if !equalSlices(first, second) {
+		t.Errorf("Expected identical shuffles for the same seed, got %v and %v", first, second)
+	}
+	if equalSlices(first, Range(0, 20).ToSlice()) {
+		t.Errorf("Expected shuffled order, got %v", first)
+	}
+
+	sum := OfSlice(first).Reduce(0, func(a, b int64) int64 { return a + b })
+	if sum != 190 {
+		t.Errorf("Expected shuffle to keep all elements, got sum %d", sum)
+	}
+}
+
+func TestSample(t *testing.T) {
+	first := Range(0, 1000).Sample(10, rand.NewSource(7)).ToSlice()
+	second := Range(0, 1000).Sample(10, rand.NewSource(7)).ToSlice()
+
+	if len(first) != 10 {
+		t.Fatalf("Expected 10 samples, got %d", len(first))
+	}
+	if !equalSlices(first, second) {
+		t.Errorf("Expected identical samples for the same seed, got %v and %v", first, second)
+	}
+	for i := 1; i < len(first); i++ {
+		if first[i-1] >= first[i] {
+			t.Errorf("Expected samples in encounter order, got %v", first)
+		}
+	}
+
+	if len(Of(1, 2, 3).Sample(10, rand.NewSource(7)).ToSlice()) != 3 {
+		t.Errorf("Expected all elements when n exceeds stream length")
+	}
+}
+
+func TestSampleFraction(t *testing.T) {
+	count := Range(0, 10000).SampleFraction(0.1, rand.NewSource(1)).Count()
+	if count < 800 || count > 1200 {
+		t.Errorf("Expected roughly 1000 samples, got %d", count)
+	}
+
+	if Range(0, 100).SampleFraction(0, rand.NewSource(1)).Count() != 0 {
+		t.Errorf("Expected no samples for p = 0")
+	}
+	if Range(0, 100).SampleFraction(1, rand.NewSource(1)).Count() != 100 {
+		t.Errorf("Expected all samples for p = 1")
+	}
+}
+
+func TestWeightedSample(t *testing.T) {
+	result := Of(1, 2, 3, 4, 5).WeightedSample(2, func(n int) float64 {
+		if n == 2 || n == 4 {
+			return 1
+		}
+		return 0
+	}, rand.NewSource(3)).ToSlice()
+
+	if !equalSlices(result, []int{2, 4}) {
+		t.Errorf("Expected only positively weighted elements [2 4], got %v", result)
+	}
+
+	heavy := 0
+	for seed := int64(0); seed < 200; seed++ {
+		picked := Of("light", "heavy").WeightedSample(1, func(s string) float64 {
+			if s == "heavy" {
+				return 9
+			}
+			return 1
+		}, rand.NewSource(seed)).ToSlice()
+		if picked[0] == "heavy" {
+			heavy++
+		}
+	}
+	if heavy < 150 {
+		t.Errorf("Expected heavy element to dominate, picked %d/200 times", heavy)
+	}
+}
+
+func TestStratifiedSample(t *testing.T) {
+	result := StratifiedSample(Range(0, 100), func(n int64) int64 {
+		return n % 3
+	}, 2, rand.NewSource(5)).ToSlice()
+
+	if len(result) != 6 {
+		t.Fatalf("Expected 6 samples, got %d", len(result))
+	}
+	counts := map[int64]int{}
+	for _, n := range result {
+		counts[n%3]++
+	}
+	for stratum, c := range counts {
+		if c != 2 {
+			t.Errorf("Expected 2 samples in stratum %d, got %d", stratum, c)
+		}
+	}
+}
+
+func TestSamplingDoesNotMaterializeUpstream(t *testing.T) {
+	const n, size = 50000, 1 << 10
+	samplers := map[string]func(Stream[[]byte]) Stream[[]byte]{
+		"Sample": func(s Stream[[]byte]) Stream[[]byte] {
+			return s.Sample(10, rand.NewSource(1))
+		},
+		"SampleFraction": func(s Stream[[]byte]) Stream[[]byte] {
+			return s.SampleFraction(0.0001, rand.NewSource(1)).Limit(1)
+		},
+		"WeightedSample": func(s Stream[[]byte]) Stream[[]byte] {
+			return s.WeightedSample(10, func(b []byte) float64 { return 1 }, rand.NewSource(1))
+		},
+		"StratifiedSample": func(s Stream[[]byte]) Stream[[]byte] {
+			return StratifiedSample(s, func(b []byte) int { return len(b) % 2 }, 10, rand.NewSource(1))
+		},
+	}
+
+	for name, sample := range samplers {
+		// 生成最后一个元素时测量存活的堆内存：把全部元素收集到内存中约为 n*size 字节
+		var live uint64
+		generated := 0
+		source := Generate(func() []byte {
+			generated++
+			if generated == n {
+				var stats runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&stats)
+				live = stats.HeapAlloc
+			}
+			return make([]byte, size)
+		}, n)
+		result := sample(source).ToSlice()
+		if len(result) == 0 {
+			t.Errorf("%s: expected samples", name)
+		}
+		if name != "SampleFraction" && live > n*size/2 {
+			t.Errorf("%s: expected upstream not to be buffered, %d bytes live", name, live)
+		}
+		if name == "SampleFraction" && generated == n {
+			t.Errorf("SampleFraction: expected Limit to stop pulling, generated %d", generated)
+		}
+	}
+}
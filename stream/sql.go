@@ -0,0 +1,104 @@
+package stream
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Querier 由 *sql.DB、*sql.Tx 和 *sql.Conn 实现
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// FromRows 使用 scanner 将每一行转换为 T，终端操作结束或短路时关闭 rows，rows.Err() 通过 Err 返回
+func FromRows[T any](rows *sql.Rows, scanner func(*sql.Rows) (T, error)) Stream[T] {
+	return newLazyStream("FromRows", func(s *streamImpl[T]) iterator[T] {
+		return scanRows(s, rows, scanner)
+	})
+}
+
+// FromRowsStruct 按 `db:"column"` 标签将列映射到结构体字段，没有对应字段的列会被忽略
+func FromRowsStruct[T any](rows *sql.Rows) Stream[T] {
+	return newLazyStream("FromRowsStruct", func(s *streamImpl[T]) iterator[T] {
+		scanner, err := structScanner[T](rows)
+		if err != nil {
+			s.fail(err)
+			rows.Close()
+			return emptyIterator[T]()
+		}
+		return scanRows(s, rows, scanner)
+	})
+}
+
+// FromQuery 在终端操作开始时执行查询，并按 FromRowsStruct 的规则映射结果
+func FromQuery[T any](ctx context.Context, db Querier, query string, args ...any) Stream[T] {
+	return newLazyStream("FromQuery", func(s *streamImpl[T]) iterator[T] {
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			s.fail(err)
+			return emptyIterator[T]()
+		}
+		scanner, err := structScanner[T](rows)
+		if err != nil {
+			s.fail(err)
+			rows.Close()
+			return emptyIterator[T]()
+		}
+		return scanRows(s, rows, scanner)
+	})
+}
+
+func scanRows[T any](s *streamImpl[T], rows *sql.Rows, scanner func(*sql.Rows) (T, error)) iterator[T] {
+	s.onClose(rows.Close)
+	return func() (T, bool) {
+		var zero T
+		if !rows.Next() {
+			s.fail(rows.Err())
+			return zero, false
+		}
+		item, err := scanner(rows)
+		if err != nil {
+			s.fail(err)
+			return zero, false
+		}
+		return item, true
+	}
+}
+
+// structScanner 根据结果集的列名预先计算每一列对应的字段
+func structScanner[T any](rows *sql.Rows) (func(*sql.Rows) (T, error), error) {
+	fields, err := structFields(reflect.TypeOf((*T)(nil)).Elem(), "db")
+	if err != nil {
+		return nil, err
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([][]int, len(columns))
+	for i, column := range columns {
+		if f, ok := lookupField(fields, column); ok {
+			indexes[i] = f.index
+		}
+	}
+
+	return func(rows *sql.Rows) (T, error) {
+		var item T
+		value := reflect.ValueOf(&item).Elem()
+		dest := make([]any, len(columns))
+		for i, index := range indexes {
+			if index == nil {
+				dest[i] = new(any)
+				continue
+			}
+			dest[i] = value.FieldByIndex(index).Addr().Interface()
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return item, fmt.Errorf("scan row: %w", err)
+		}
+		return item, nil
+	}, nil
+}
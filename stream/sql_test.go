@@ -0,0 +1,143 @@
+package stream
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeDriver 返回固定的结果集，用于在没有真实数据库的情况下测试 sql 数据源
+type fakeDriver struct {
+	columns []string
+	rows    [][]driver.Value
+	err     error
+	closed  atomic.Int32
+}
+
+type fakeConn struct{ driver *fakeDriver }
+
+type fakeRows struct {
+	driver *fakeDriver
+	next   int
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return &fakeRows{driver: c.driver}, nil
+}
+
+func (r *fakeRows) Columns() []string { return r.driver.columns }
+
+func (r *fakeRows) Close() error {
+	r.driver.closed.Add(1)
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.driver.rows) {
+		if r.driver.err != nil {
+			return r.driver.err
+		}
+		return io.EOF
+	}
+	copy(dest, r.driver.rows[r.next])
+	r.next++
+	return nil
+}
+
+var fakeDriverCount atomic.Int32
+
+func openFakeDB(t *testing.T, d *fakeDriver) *sql.DB {
+	name := "stream-fake-" + IntToString(int64(fakeDriverCount.Add(1)))
+	sql.Register(name, d)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+type sqlUser struct {
+	ID    int64  `db:"id"`
+	Name  string `db:"name"`
+	Score float64
+}
+
+func newUserDriver() *fakeDriver {
+	return &fakeDriver{
+		columns: []string{"id", "name", "score", "ignored"},
+		rows: [][]driver.Value{
+			{int64(1), "alice", 9.5, "x"},
+			{int64(2), "bob", 3.0, "y"},
+			{int64(3), "carol", 7.25, "z"},
+		},
+	}
+}
+
+func TestFromQuery(t *testing.T) {
+	d := newUserDriver()
+	db := openFakeDB(t, d)
+
+	users := FromQuery[sqlUser](context.Background(), db, "SELECT * FROM users").
+		Filter(func(u sqlUser) bool { return u.Score > 5 }).
+		ToSlice()
+
+	if len(users) != 2 || users[1].Name != "carol" || users[1].Score != 7.25 {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+	if d.closed.Load() != 1 {
+		t.Errorf("Expected rows to be closed once, got %d", d.closed.Load())
+	}
+}
+
+func TestFromRowsClosedOnShortCircuit(t *testing.T) {
+	d := newUserDriver()
+	db := openFakeDB(t, d)
+	rows, err := db.Query("SELECT * FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := FromRows(rows, func(r *sql.Rows) (string, error) {
+		var id int64
+		var name, ignored string
+		var score float64
+		err := r.Scan(&id, &name, &score, &ignored)
+		return name, err
+	}).FindFirst()
+
+	if first.Get() != "alice" {
+		t.Errorf("Expected alice, got %s", first.Get())
+	}
+	if d.closed.Load() != 1 {
+		t.Errorf("Expected rows to be closed after FindFirst")
+	}
+}
+
+func TestFromRowsStructErr(t *testing.T) {
+	d := newUserDriver()
+	d.err = errors.New("connection reset")
+	db := openFakeDB(t, d)
+	rows, err := db.Query("SELECT * FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := FromRowsStruct[sqlUser](rows)
+	if s.Count() != 3 {
+		t.Errorf("Expected 3 rows before the error")
+	}
+	if s.Err() == nil || s.Err().Error() != "connection reset" {
+		t.Errorf("Expected rows.Err() to be surfaced, got %v", s.Err())
+	}
+}
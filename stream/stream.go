@@ -1,5 +1,12 @@
 package stream
 
+import (
+	"log/slog"
+	"math/rand"
+	"slices"
+	"time"
+)
+
 type Stream[T any] interface {
 	Filter(predicate Predicate[T]) Stream[T]
 	Map(mapper Function[T, T]) Stream[T]
@@ -20,179 +27,321 @@ type Stream[T any] interface {
 	FindFirst() Optional[T]
 	FindAny() Optional[T]
 	ToSlice() []T
-
+	Max(comparator Comparator[T]) Optional[T]
+	Min(comparator Comparator[T]) Optional[T]
+	MinMax(comparator Comparator[T]) (Optional[T], Optional[T])
+	TopK(k int, comparator Comparator[T]) []T
+	BottomK(k int, comparator Comparator[T]) []T
+	Shuffle(source rand.Source) Stream[T]
+	Sample(n int, source rand.Source) Stream[T]
+	SampleFraction(p float64, source rand.Source) Stream[T]
+	WeightedSample(n int, weight Function[T, float64], source rand.Source) Stream[T]
+	Log(logger *slog.Logger, level slog.Level, msg string, attrs func(T) []slog.Attr, opts ...LogOption) Stream[T]
+	LogSummary(logger *slog.Logger, level slog.Level, msg string) Stream[T]
+	RateLimit(n int, per time.Duration, opts ...TimingOption) Stream[T]
+	Throttle(interval time.Duration, opts ...TimingOption) Stream[T]
+	Debounce(quiet time.Duration, opts ...TimingOption) Stream[T]
+	SampleInterval(interval time.Duration, opts ...TimingOption) Stream[T]
+	Delay(d time.Duration, opts ...TimingOption) Stream[T]
+	Observe(observer Observer, opts ...ObserveOption) Stream[T]
+	Stats() PipelineStats
+	Err() error
+	Recover() Stream[T]
+	OnPanic(handler PanicHandler[T]) Stream[T]
+
+	Label(label string) Stream[T]
+	Explain() string
+	ToDOT() string
+	Unoptimized() Stream[T]
+
+	Characteristics() Characteristics[T]
+	EstimateSize() int64
+
+	pipe(name string, op func(iterator[T]) iterator[T]) Stream[T]
+	apply(name string, op func([]T) []T) Stream[T]
+	applyStreaming(name string, op func(iterator[T]) []T) Stream[T]
+	sortWith(comparator Comparator[T], ordering any) Stream[T]
 	execute() []T
+	iterate() iterator[T]
+	close()
+	plan() []*planNode
 }
 
 type streamImpl[T any] struct {
 	source     []T
-	operations []func([]T) []T
+	at         func(i int64) T
+	open       func(s *streamImpl[T]) iterator[T]
+	origin     stageInfo
+	upstream   planner
+	inputs     []planner
+	traits     Characteristic
+	size       int64
+	sortedBy   Comparator[T]
+	ordering   any
+	operations []stage[T]
+	closers    []func() error
+	err        error
+	errCount   int64
 	isConsumed bool
+	isClosed   bool
+
+	unoptimized       bool
+	panicHandler      PanicHandler[T]
+	resumesAfterPanic bool
+
+	instrumented  bool
+	observers     []Observer
+	observeConfig observeConfig
+	stats         *PipelineStats
 }
 
-func newStream[T any](source []T) Stream[T] {
+func newStream[T any](name string, source []T) *streamImpl[T] {
 	return &streamImpl[T]{
 		source:     source,
-		operations: make([]func([]T) []T, 0),
+		origin:     describeSource(name),
+		traits:     Sized | Ordered,
+		size:       int64(len(source)),
+		operations: make([]stage[T], 0),
 	}
 }
 
+// newLazyStream 创建惰性数据源的流，open 在终端操作开始时才会被调用
+func newLazyStream[T any](name string, open func(s *streamImpl[T]) iterator[T]) *streamImpl[T] {
+	return &streamImpl[T]{
+		open:       open,
+		origin:     describeSource(name),
+		traits:     Ordered,
+		size:       -1,
+		operations: make([]stage[T], 0),
+	}
+}
+
+// derive 基于上游流创建元素类型不同的新流，上游在新流的终端操作中被消费，并随新流一起关闭
+func derive[T, R any](name string, upstream Stream[T], op func(s *streamImpl[R], upstream iterator[T]) iterator[R]) *streamImpl[R] {
+	s := newLazyStream(name, func(s *streamImpl[R]) iterator[R] {
+		s.onClose(func() error {
+			upstream.close()
+			return upstream.Err()
+		})
+		return op(s, upstream.iterate())
+	})
+	s.upstream = upstream
+	return s
+}
+
 func (s *streamImpl[T]) Filter(predicate Predicate[T]) Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
-		// 预分配切片容量，最坏情况与原切片大小相同
-		result := make([]T, 0, len(items))
-		for _, item := range items {
-			if predicate(item) {
-				result = append(result, item)
+	return s.push(stage[T]{stageInfo: stageInfo{name: "Filter"}, predicate: predicate, apply: func(upstream iterator[T]) iterator[T] {
+		return func() (T, bool) {
+			for item, ok := upstream(); ok; item, ok = upstream() {
+				if predicate(item) {
+					return item, true
+				}
 			}
+			var zero T
+			return zero, false
 		}
-		return result
-	})
-	return s
+	}})
 }
 
 func (s *streamImpl[T]) Map(mapper Function[T, T]) Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
-		result := make([]T, len(items))
-		for i, item := range items {
-			result[i] = mapper(item)
+	return s.push(stage[T]{stageInfo: stageInfo{name: "Map"}, mapper: mapper, apply: func(upstream iterator[T]) iterator[T] {
+		return func() (T, bool) {
+			item, ok := upstream()
+			if !ok {
+				return item, false
+			}
+			return mapper(item), true
 		}
-		return result
-	})
-	return s
+	}})
 }
 
 func (s *streamImpl[T]) FlatMap(mapper Function[T, Stream[T]]) Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
-		// 预分配切片容量，基于原切片大小估计
-		result := make([]T, 0, len(items))
-		for _, item := range items {
-			stream := mapper(item)
-			result = append(result, stream.ToSlice()...)
+	var inner Stream[T]
+	// 提前结束时关闭尚未耗尽的内层流
+	s.onClose(func() error {
+		if inner == nil {
+			return nil
+		}
+		inner.close()
+		return inner.Err()
+	})
+	return s.pipe("FlatMap", func(upstream iterator[T]) iterator[T] {
+		var current iterator[T]
+		return func() (T, bool) {
+			for {
+				if current != nil {
+					if item, ok := current(); ok {
+						return item, true
+					}
+					inner.close()
+					s.fail(inner.Err())
+					inner, current = nil, nil
+				}
+				item, ok := upstream()
+				if !ok {
+					var zero T
+					return zero, false
+				}
+				inner = mapper(item)
+				current = inner.iterate()
+			}
 		}
-		return result
 	})
-	return s
 }
 
 func (s *streamImpl[T]) Distinct() Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
+	return s.pipe("Distinct", func(upstream iterator[T]) iterator[T] {
 		seen := make(map[any]bool)
-		result := make([]T, 0)
-		for _, item := range items {
-			if !seen[item] {
-				seen[item] = true
-				result = append(result, item)
+		return func() (T, bool) {
+			for item, ok := upstream(); ok; item, ok = upstream() {
+				if !seen[item] {
+					seen[item] = true
+					return item, true
+				}
 			}
+			var zero T
+			return zero, false
 		}
-		return result
 	})
-	return s
 }
 
 func (s *streamImpl[T]) Sorted(comparator Comparator[T]) Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
-		result := make([]T, len(items))
-		copy(result, items)
-		sortSlice(result, comparator)
-		return result
-	})
-	return s
+	return s.sortWith(comparator, nil)
 }
 
-func sortSlice[T any](slice []T, comparator Comparator[T]) {
-	quickSort(slice, 0, len(slice)-1, comparator)
-}
-
-func quickSort[T any](slice []T, low, high int, comparator Comparator[T]) {
-	if low < high {
-		pivotIndex := partition(slice, low, high, comparator)
-		quickSort(slice, low, pivotIndex-1, comparator)
-		quickSort(slice, pivotIndex+1, high, comparator)
+// sortWith 追加 Sorted 阶段，ordering 标识排序依据，比较器本身无法判断是否相同
+func (s *streamImpl[T]) sortWith(comparator Comparator[T], ordering any) Stream[T] {
+	sorted := func(items []T) []T {
+		sortSlice(items, comparator)
+		return items
 	}
+	return s.push(stage[T]{stageInfo: stageInfo{name: "Sorted", barrier: true}, comparator: comparator, ordering: ordering, apply: barrier(sorted)})
 }
 
-func partition[T any](slice []T, low, high int, comparator Comparator[T]) int {
-	pivot := slice[high]
-	i := low - 1
-
-	for j := low; j < high; j++ {
-		if comparator(slice[j], pivot) <= 0 {
-			i++
-			slice[i], slice[j] = slice[j], slice[i]
-		}
-	}
-
-	slice[i+1], slice[high] = slice[high], slice[i+1]
-	return i + 1
+// sortSlice 稳定排序，比较结果相等的元素保持原来的顺序
+func sortSlice[T any](slice []T, comparator Comparator[T]) {
+	slices.SortStableFunc(slice, comparator)
 }
 
 func (s *streamImpl[T]) Limit(maxSize int64) Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
-		if int64(len(items)) <= maxSize {
-			return items
+	return s.push(stage[T]{stageInfo: stageInfo{name: "Limit", shortCircuit: true}, n: maxSize, apply: func(upstream iterator[T]) iterator[T] {
+		var taken int64
+		return func() (T, bool) {
+			if taken >= maxSize {
+				var zero T
+				return zero, false
+			}
+			item, ok := upstream()
+			if ok {
+				taken++
+			}
+			return item, ok
 		}
-		return items[:maxSize]
-	})
-	return s
+	}})
 }
 
 func (s *streamImpl[T]) Skip(n int64) Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
-		if int64(len(items)) <= n {
-			return []T{}
+	return s.push(stage[T]{stageInfo: stageInfo{name: "Skip"}, n: n, apply: func(upstream iterator[T]) iterator[T] {
+		skipped := false
+		return func() (T, bool) {
+			if !skipped {
+				skipped = true
+				for i := int64(0); i < n; i++ {
+					if _, ok := upstream(); !ok {
+						var zero T
+						return zero, false
+					}
+				}
+			}
+			return upstream()
 		}
-		return items[n:]
-	})
-	return s
+	}})
 }
 
 func (s *streamImpl[T]) Peek(consumer Consumer[T]) Stream[T] {
-	s.checkNotConsumed()
-	s.operations = append(s.operations, func(items []T) []T {
-		for _, item := range items {
-			consumer(item)
+	return s.pipe("Peek", func(upstream iterator[T]) iterator[T] {
+		return func() (T, bool) {
+			item, ok := upstream()
+			if ok {
+				consumer(item)
+			}
+			return item, ok
 		}
-		return items
 	})
+}
+
+// pipe 追加一个逐元素的惰性中间操作
+func (s *streamImpl[T]) pipe(name string, op func(iterator[T]) iterator[T]) Stream[T] {
+	return s.push(stage[T]{stageInfo: stageInfo{name: name}, apply: op})
+}
+
+// apply 追加一个需要看到全部元素的中间操作，供包级泛型函数使用
+func (s *streamImpl[T]) apply(name string, op func([]T) []T) Stream[T] {
+	return s.push(stage[T]{stageInfo: stageInfo{name: name, barrier: true}, apply: barrier(op)})
+}
+
+// applyStreaming 与 apply 相同，但由 op 自己从上游拉取元素
+func (s *streamImpl[T]) applyStreaming(name string, op func(iterator[T]) []T) Stream[T] {
+	return s.push(stage[T]{stageInfo: stageInfo{name: name, barrier: true}, apply: gather(op)})
+}
+
+// push 追加中间操作，并记录用户代码中追加该操作的位置
+func (s *streamImpl[T]) push(st stage[T]) Stream[T] {
+	s.checkNotConsumed()
+	st.callers = captureCallers()
+	s.operations = append(s.operations, st)
 	return s
 }
 
 func (s *streamImpl[T]) ForEach(consumer Consumer[T]) {
-	items := s.execute()
-	for _, item := range items {
+	it := s.iterate()
+	defer s.close()
+	for item, ok := it(); ok; item, ok = it() {
 		consumer(item)
 	}
 }
 
 func (s *streamImpl[T]) Collect(collector Collector[T, any, any]) any {
+	if c, ok := collector.(iteratorCollector[T]); ok {
+		size := s.exactSize()
+		it := s.iterate()
+		defer s.close()
+		return c.collectFrom(it, size)
+	}
 	items := s.execute()
 	return collector.Collect(items)
 }
 
 func (s *streamImpl[T]) Reduce(identity T, accumulator BinaryOperator[T]) T {
-	items := s.execute()
+	it := s.iterate()
+	defer s.close()
 	result := identity
-	for _, item := range items {
+	for item, ok := it(); ok; item, ok = it() {
 		result = accumulator(result, item)
 	}
 	return result
 }
 
 func (s *streamImpl[T]) Count() int64 {
-	return int64(len(s.execute()))
+	if size, ok := s.knownSize(); ok {
+		s.checkNotConsumed()
+		s.isConsumed = true
+		s.close()
+		return size
+	}
+	it := s.iterate()
+	defer s.close()
+	var count int64
+	for _, ok := it(); ok; _, ok = it() {
+		count++
+	}
+	return count
 }
 
 func (s *streamImpl[T]) AnyMatch(predicate Predicate[T]) bool {
-	items := s.execute()
-	for _, item := range items {
+	it := s.iterate()
+	defer s.close()
+	for item, ok := it(); ok; item, ok = it() {
 		if predicate(item) {
 			return true
 		}
@@ -201,8 +350,9 @@ func (s *streamImpl[T]) AnyMatch(predicate Predicate[T]) bool {
 }
 
 func (s *streamImpl[T]) AllMatch(predicate Predicate[T]) bool {
-	items := s.execute()
-	for _, item := range items {
+	it := s.iterate()
+	defer s.close()
+	for item, ok := it(); ok; item, ok = it() {
 		if !predicate(item) {
 			return false
 		}
@@ -215,11 +365,12 @@ func (s *streamImpl[T]) NoneMatch(predicate Predicate[T]) bool {
 }
 
 func (s *streamImpl[T]) FindFirst() Optional[T] {
-	items := s.execute()
-	if len(items) == 0 {
-		return EmptyOptional[T]()
+	it := s.iterate()
+	defer s.close()
+	if item, ok := it(); ok {
+		return OfOptional(item)
 	}
-	return OfOptional(items[0])
+	return EmptyOptional[T]()
 }
 
 func (s *streamImpl[T]) FindAny() Optional[T] {
@@ -230,20 +381,117 @@ func (s *streamImpl[T]) ToSlice() []T {
 	return s.execute()
 }
 
+func (s *streamImpl[T]) Err() error {
+	return s.err
+}
+
+func (s *streamImpl[T]) Max(comparator Comparator[T]) Optional[T] {
+	_, largest := s.MinMax(comparator)
+	return largest
+}
+
+func (s *streamImpl[T]) Min(comparator Comparator[T]) Optional[T] {
+	smallest, _ := s.MinMax(comparator)
+	return smallest
+}
+
+// MinMax 单次遍历同时求最小值和最大值，相等元素保留最先出现的一个
+func (s *streamImpl[T]) MinMax(comparator Comparator[T]) (Optional[T], Optional[T]) {
+	it := s.iterate()
+	defer s.close()
+	smallest, ok := it()
+	if !ok {
+		return EmptyOptional[T](), EmptyOptional[T]()
+	}
+	largest := smallest
+	for item, ok := it(); ok; item, ok = it() {
+		if comparator(item, smallest) < 0 {
+			smallest = item
+		}
+		if comparator(item, largest) > 0 {
+			largest = item
+		}
+	}
+	return OfOptional(smallest), OfOptional(largest)
+}
+
+// TopK 返回最大的 k 个元素，按从大到小排列，仅占用 O(k) 的额外内存
+func (s *streamImpl[T]) TopK(k int, comparator Comparator[T]) []T {
+	it := s.iterate()
+	defer s.close()
+	return topK(it, k, comparator)
+}
+
+// BottomK 返回最小的 k 个元素，按从小到大排列
+func (s *streamImpl[T]) BottomK(k int, comparator Comparator[T]) []T {
+	return s.TopK(k, reverseComparator(comparator))
+}
+
 func (s *streamImpl[T]) execute() []T {
+	size := s.exactSize()
+	it := s.iterate()
+	defer s.close()
+	return drain(it, size)
+}
+
+// iterate 标记流已被消费，并按顺序把所有中间操作串联到数据源上
+func (s *streamImpl[T]) iterate() iterator[T] {
 	if s.isConsumed {
 		panic("stream has already been operated upon or closed")
 	}
 	s.isConsumed = true
 
-	result := make([]T, len(s.source))
-	copy(result, s.source)
+	origin, bounds, ops := s.optimizedPlan()
+	var it iterator[T]
+	switch {
+	case s.open != nil:
+		it = s.open(s)
+	case s.at != nil:
+		it = indexIterator(s.at, bounds.from, bounds.to)
+	default:
+		it = sliceIterator(s.source[bounds.from:bounds.to])
+	}
+
+	if s.panicHandler != nil {
+		it, ops = s.recoverPanics(origin, it, ops)
+	}
 
-	for _, op := range s.operations {
-		result = op(result)
+	if s.isInstrumented() {
+		return s.instrument(it, ops)
 	}
 
-	return result
+	for _, op := range ops {
+		it = op.apply(it)
+	}
+
+	return it
+}
+
+// close 释放数据源持有的资源，关闭过程中的错误可以通过 Err 获取
+func (s *streamImpl[T]) close() {
+	if s.isClosed {
+		return
+	}
+	s.isClosed = true
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.fail(s.closers[i]())
+	}
+}
+
+// fail 记录第一个发生的错误
+func (s *streamImpl[T]) fail(err error) {
+	if err == nil {
+		return
+	}
+	s.errCount++
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// onClose 注册在终端操作结束时执行的清理函数
+func (s *streamImpl[T]) onClose(closer func() error) {
+	s.closers = append(s.closers, closer)
 }
 
 func (s *streamImpl[T]) checkNotConsumed() {
@@ -0,0 +1,180 @@
+package stream
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Match 是一次匹配的文本及其在输入中的字节区间 [Start, End)；未参与匹配的分组 Start 和 End 为 -1
+type Match struct {
+	Text  string
+	Start int
+	End   int
+}
+
+// Runes 惰性地按 UTF-8 解码 s，非法字节解码为 utf8.RuneError
+func Runes(s string) Stream[rune] {
+	return newLazyStream("Runes", func(_ *streamImpl[rune]) iterator[rune] {
+		pos := 0
+		return func() (rune, bool) {
+			if pos >= len(s) {
+				return 0, false
+			}
+			r, width := utf8.DecodeRuneInString(s[pos:])
+			pos += width
+			return r, true
+		}
+	})
+}
+
+// Bytes 按顺序产生 b 中的每个字节，不复制 b
+func Bytes(b []byte) Stream[byte] {
+	s := newLazyStream("Bytes", func(_ *streamImpl[byte]) iterator[byte] {
+		return sliceIterator(b)
+	})
+	s.traits |= Sized
+	s.size = int64(len(b))
+	return s
+}
+
+// Split 与 strings.Split 语义相同，但惰性地逐段产生子串
+func Split(s, sep string) Stream[string] {
+	return newLazyStream("Split", func(_ *streamImpl[string]) iterator[string] {
+		done := false
+		return func() (string, bool) {
+			if done {
+				return "", false
+			}
+			if sep == "" {
+				if s == "" {
+					done = true
+					return "", false
+				}
+				_, width := utf8.DecodeRuneInString(s)
+				part := s[:width]
+				s = s[width:]
+				return part, true
+			}
+			i := strings.Index(s, sep)
+			if i < 0 {
+				done = true
+				return s, true
+			}
+			part := s[:i]
+			s = s[i+len(sep):]
+			return part, true
+		}
+	})
+}
+
+// Fields 与 strings.Fields 语义相同，按 unicode.IsSpace 切分并忽略空白
+func Fields(s string) Stream[string] {
+	return newLazyStream("Fields", func(_ *streamImpl[string]) iterator[string] {
+		return func() (string, bool) {
+			s = strings.TrimLeftFunc(s, unicode.IsSpace)
+			if s == "" {
+				return "", false
+			}
+			end := strings.IndexFunc(s, unicode.IsSpace)
+			if end < 0 {
+				end = len(s)
+			}
+			field := s[:end]
+			s = s[end:]
+			return field, true
+		}
+	})
+}
+
+// RegexpMatches 惰性地产生 re 在 s 中所有不重叠的匹配，结果与 re.FindAllStringIndex 一致
+func RegexpMatches(re *regexp.Regexp, s string) Stream[Match] {
+	return derive("RegexpMatches", RegexpSubmatches(re, s), func(_ *streamImpl[Match], upstream iterator[[]Match]) iterator[Match] {
+		return func() (Match, bool) {
+			groups, ok := upstream()
+			if !ok {
+				return Match{}, false
+			}
+			return groups[0], true
+		}
+	})
+}
+
+// RegexpSubmatches 惰性地产生每次匹配的全部分组，下标 0 为整个匹配
+func RegexpSubmatches(re *regexp.Regexp, s string) Stream[[]Match] {
+	return newLazyStream("RegexpSubmatches", func(_ *streamImpl[[]Match]) iterator[[]Match] {
+		if dependsOnPrecedingText(re) {
+			// 依赖前文的断言无法在子串上重新匹配，退化为一次性计算所有下标
+			return sliceIterator(toMatches(s, re.FindAllStringSubmatchIndex(s, -1), 0))
+		}
+		pos, prevEnd := 0, -1
+		return func() ([]Match, bool) {
+			for pos <= len(s) {
+				loc := re.FindStringSubmatchIndex(s[pos:])
+				if loc == nil {
+					break
+				}
+				offset := pos
+				start, end := loc[0]+offset, loc[1]+offset
+				if start == end {
+					// 与 FindAll 一致：紧接在上一个匹配之后的空匹配被忽略，空匹配后前进一个字符
+					pos = end + 1
+					if end < len(s) {
+						_, width := utf8.DecodeRuneInString(s[end:])
+						pos = end + width
+					}
+					if start == prevEnd {
+						continue
+					}
+				} else {
+					pos = end
+				}
+				prevEnd = end
+				return toMatches(s, [][]int{loc}, offset)[0], true
+			}
+			pos = len(s) + 1
+			return nil, false
+		}
+	})
+}
+
+func toMatches(s string, locs [][]int, offset int) [][]Match {
+	result := make([][]Match, len(locs))
+	for i, loc := range locs {
+		groups := make([]Match, len(loc)/2)
+		for g := range groups {
+			start, end := loc[2*g], loc[2*g+1]
+			if start < 0 {
+				groups[g] = Match{Start: -1, End: -1}
+				continue
+			}
+			groups[g] = Match{Text: s[start+offset : end+offset], Start: start + offset, End: end + offset}
+		}
+		result[i] = groups
+	}
+	return result
+}
+
+// dependsOnPrecedingText 判断正则是否包含 ^、\A、\b、\B 等依赖匹配位置之前内容的断言
+func dependsOnPrecedingText(re *regexp.Regexp) bool {
+	parsed, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return true
+	}
+	var walk func(*syntax.Regexp) bool
+	walk = func(r *syntax.Regexp) bool {
+		switch r.Op {
+		case syntax.OpBeginLine, syntax.OpBeginText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+			return true
+		}
+		for _, sub := range r.Sub {
+			if walk(sub) {
+				return true
+			}
+		}
+		return false
+	}
+	return walk(parsed)
+}
@@ -0,0 +1,94 @@
+package stream
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRunesAndBytes(t *testing.T) {
+	runes := Runes("héllo, 世界").ToSlice()
+	if string(runes) != "héllo, 世界" || len(runes) != 9 {
+		t.Errorf("Unexpected runes: %q", string(runes))
+	}
+
+	count := Bytes([]byte("a,b,c")).Filter(func(b byte) bool { return b == ',' }).Count()
+	if count != 2 {
+		t.Errorf("Expected 2 commas, got %d", count)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	cases := []struct{ s, sep string }{
+		{"a,b,,c", ","},
+		{"a,b,c,", ","},
+		{"", ","},
+		{"abc", ""},
+		{"a::b::c", "::"},
+		{"no separator", ","},
+	}
+	for _, c := range cases {
+		result := Split(c.s, c.sep).ToSlice()
+		expected := strings.Split(c.s, c.sep)
+		if !equalSlices(result, expected) {
+			t.Errorf("Split(%q, %q): expected %q, got %q", c.s, c.sep, expected, result)
+		}
+	}
+}
+
+func TestFields(t *testing.T) {
+	input := "  the quick\tbrown \n fox  "
+	result := Fields(input).ToSlice()
+	if !equalSlices(result, strings.Fields(input)) {
+		t.Errorf("Expected %q, got %q", strings.Fields(input), result)
+	}
+
+	frequency := Fields("a b a c a b").Collect(GroupingBy(func(w string) string { return w })).(map[string][]string)
+	if len(frequency["a"]) != 3 || len(frequency["b"]) != 2 {
+		t.Errorf("Unexpected word frequency: %v", frequency)
+	}
+}
+
+func TestRegexpMatches(t *testing.T) {
+	cases := []struct{ pattern, input string }{
+		{`\d+`, "a1b22c333"},
+		{`a*`, "baaac"},
+		{`x*`, "héllo"},
+		{`^\w+`, "first line\nsecond"},
+		{`(?m)^\w+`, "first line\nsecond"},
+		{`\bgo\b`, "go gopher go"},
+		{`\{\{\s*(\w+)\s*\}\}`, "Hello {{ name }}, welcome to {{place}}"},
+	}
+	for _, c := range cases {
+		re := regexp.MustCompile(c.pattern)
+		matches := RegexpMatches(re, c.input).ToSlice()
+		expected := re.FindAllStringIndex(c.input, -1)
+		if len(matches) != len(expected) {
+			t.Errorf("%s on %q: expected %v, got %+v", c.pattern, c.input, expected, matches)
+			continue
+		}
+		for i, m := range matches {
+			if m.Start != expected[i][0] || m.End != expected[i][1] || m.Text != c.input[m.Start:m.End] {
+				t.Errorf("%s on %q: expected %v at %d, got %+v", c.pattern, c.input, expected[i], i, m)
+			}
+		}
+	}
+}
+
+func TestRegexpSubmatches(t *testing.T) {
+	re := regexp.MustCompile(`(\w+)=(\d+)?`)
+	result := RegexpSubmatches(re, "a=1 b= c=3").ToSlice()
+
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 matches, got %d", len(result))
+	}
+	if result[0][1].Text != "a" || result[0][2].Text != "1" || result[0][2].Start != 2 {
+		t.Errorf("Unexpected first match: %+v", result[0])
+	}
+	if result[1][2].Start != -1 {
+		t.Errorf("Expected unmatched group to have Start -1, got %+v", result[1][2])
+	}
+	if result[2][2].Text != "3" || result[2][2].Start != 9 {
+		t.Errorf("Unexpected last match: %+v", result[2])
+	}
+}
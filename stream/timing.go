@@ -0,0 +1,182 @@
+package stream
+
+import (
+	"context"
+	"time"
+)
+
+// TimingOption 配置 RateLimit、Throttle、Debounce、SampleInterval 和 Delay 使用的时钟和 context
+type TimingOption func(*timingConfig)
+
+type timingConfig struct {
+	clock Clock
+	ctx   context.Context
+}
+
+// WithClock 指定时间相关操作使用的时钟，默认为 SystemClock
+func WithClock(clock Clock) TimingOption {
+	return func(c *timingConfig) {
+		c.clock = clock
+	}
+}
+
+// WithContext 指定等待时使用的 context，取消后正在进行的等待立即结束，流随之结束，Err 返回 ctx.Err()；
+// 默认为 context.Background()，等待无法取消
+func WithContext(ctx context.Context) TimingOption {
+	return func(c *timingConfig) {
+		c.ctx = ctx
+	}
+}
+
+func newTimingConfig(opts []TimingOption) timingConfig {
+	cfg := timingConfig{clock: SystemClock(), ctx: context.Background()}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.clock == nil {
+		cfg.clock = SystemClock()
+	}
+	if cfg.ctx == nil {
+		cfg.ctx = context.Background()
+	}
+	return cfg
+}
+
+// RateLimit 以令牌桶限制元素的产生速率：每 per 时间内最多 n 个元素，允许 n 个元素的突发；
+// 超出速率时在交给下游之前等待，不会丢弃元素
+func (s *streamImpl[T]) RateLimit(n int, per time.Duration, opts ...TimingOption) Stream[T] {
+	cfg := newTimingConfig(opts)
+	// 按 GCRA 计算：tat 为理论上下一个元素的到达时间，比它早 tolerance 以内的元素可以立即通过
+	interval := per / time.Duration(max(n, 1))
+	tolerance := per - interval
+	return s.pipe("RateLimit", func(upstream iterator[T]) iterator[T] {
+		var tat time.Time
+		return func() (T, bool) {
+			item, ok := upstream()
+			if !ok {
+				return item, false
+			}
+			now := cfg.clock.Now()
+			if tat.Before(now) {
+				tat = now
+			}
+			if wait := tat.Add(-tolerance).Sub(now); wait > 0 {
+				if err := cfg.clock.Sleep(cfg.ctx, wait); err != nil {
+					s.fail(err)
+					var zero T
+					return zero, false
+				}
+			}
+			tat = tat.Add(interval)
+			return item, true
+		}
+	})
+}
+
+// Throttle 产生一个元素后，丢弃距离它不足 interval 到达的元素
+func (s *streamImpl[T]) Throttle(interval time.Duration, opts ...TimingOption) Stream[T] {
+	cfg := newTimingConfig(opts)
+	return s.pipe("Throttle", func(upstream iterator[T]) iterator[T] {
+		var last time.Time
+		emitted := false
+		return func() (T, bool) {
+			for item, ok := upstream(); ok; item, ok = upstream() {
+				now := cfg.clock.Now()
+				if !emitted || now.Sub(last) >= interval {
+					last, emitted = now, true
+					return item, true
+				}
+			}
+			var zero T
+			return zero, false
+		}
+	})
+}
+
+// Debounce 只产生之后 quiet 时间内没有新元素到达的元素，最后一个元素总是被产生；
+// 拉取模式下需要等到下一个元素到达（或上游结束）才能确定，因此元素会推迟到那时才交给下游
+func (s *streamImpl[T]) Debounce(quiet time.Duration, opts ...TimingOption) Stream[T] {
+	cfg := newTimingConfig(opts)
+	return s.pipe("Debounce", func(upstream iterator[T]) iterator[T] {
+		var pending T
+		var pendingAt time.Time
+		hasPending, exhausted := false, false
+		return func() (T, bool) {
+			var zero T
+			for !exhausted {
+				item, ok := upstream()
+				if !ok {
+					exhausted = true
+					break
+				}
+				now := cfg.clock.Now()
+				previous, quietEnough := pending, hasPending && now.Sub(pendingAt) >= quiet
+				pending, pendingAt, hasPending = item, now, true
+				if quietEnough {
+					return previous, true
+				}
+			}
+			if hasPending {
+				hasPending = false
+				return pending, true
+			}
+			return zero, false
+		}
+	})
+}
+
+// SampleInterval 把时间从第一次拉取开始划分为长度为 interval 的区间，每个有元素到达的区间产生其中最后到达的元素；
+// 与 Debounce 一样，区间的结果要等到下一个区间的元素到达（或上游结束）时才交给下游
+func (s *streamImpl[T]) SampleInterval(interval time.Duration, opts ...TimingOption) Stream[T] {
+	cfg := newTimingConfig(opts)
+	interval = max(interval, 1)
+	return s.pipe("SampleInterval", func(upstream iterator[T]) iterator[T] {
+		var start time.Time
+		var latest T
+		var window int64
+		started, hasLatest, exhausted := false, false, false
+		return func() (T, bool) {
+			var zero T
+			if !started {
+				start, started = cfg.clock.Now(), true
+			}
+			for !exhausted {
+				item, ok := upstream()
+				if !ok {
+					exhausted = true
+					break
+				}
+				current := int64(cfg.clock.Now().Sub(start) / interval)
+				previous, closed := latest, hasLatest && current > window
+				latest, window, hasLatest = item, current, true
+				if closed {
+					return previous, true
+				}
+			}
+			if hasLatest {
+				hasLatest = false
+				return latest, true
+			}
+			return zero, false
+		}
+	})
+}
+
+// Delay 在每个元素到达后等待 d 再交给下游
+func (s *streamImpl[T]) Delay(d time.Duration, opts ...TimingOption) Stream[T] {
+	cfg := newTimingConfig(opts)
+	return s.pipe("Delay", func(upstream iterator[T]) iterator[T] {
+		return func() (T, bool) {
+			item, ok := upstream()
+			if !ok {
+				return item, false
+			}
+			if err := cfg.clock.Sleep(cfg.ctx, d); err != nil {
+				s.fail(err)
+				var zero T
+				return zero, false
+			}
+			return item, true
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// arrivals 产生在虚拟时间 offsets[i]（毫秒，相对于 clock 的起始时间）到达的元素 i
+func arrivals(clock *VirtualClock, offsets ...int) Stream[int] {
+	start := clock.Now()
+	i := 0
+	return Generate(func() int {
+		if wait := start.Add(time.Duration(offsets[i]) * time.Millisecond).Sub(clock.Now()); wait > 0 {
+			clock.Sleep(context.Background(), wait)
+		}
+		i++
+		return i - 1
+	}, len(offsets))
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	var emitted []time.Duration
+	Of(1, 2, 3, 4, 5).RateLimit(2, time.Second, WithClock(clock)).ForEach(func(int) {
+		emitted = append(emitted, clock.Now().Sub(time.Unix(0, 0)))
+	})
+	expected := []time.Duration{0, 0, 500 * time.Millisecond, time.Second, 1500 * time.Millisecond}
+	if !equalSlices(emitted, expected) {
+		t.Errorf("Expected %v, got %v", expected, emitted)
+	}
+
+	// 空闲之后令牌桶重新装满，但不会超过 n 个
+	clock = NewVirtualClock(time.Unix(0, 0))
+	emitted = nil
+	arrivals(clock, 0, 5000, 5000, 5000).RateLimit(2, time.Second, WithClock(clock)).ForEach(func(int) {
+		emitted = append(emitted, clock.Now().Sub(time.Unix(0, 0)))
+	})
+	expected = []time.Duration{0, 5 * time.Second, 5 * time.Second, 5500 * time.Millisecond}
+	if !equalSlices(emitted, expected) {
+		t.Errorf("Expected %v, got %v", expected, emitted)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	result := arrivals(clock, 0, 50, 99, 100, 150, 250).Throttle(100*time.Millisecond, WithClock(clock)).ToSlice()
+	if !equalSlices(result, []int{0, 3, 5}) {
+		t.Errorf("Expected [0 3 5], got %v", result)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	result := arrivals(clock, 0, 10, 20, 200, 210, 500).Debounce(100*time.Millisecond, WithClock(clock)).ToSlice()
+	if !equalSlices(result, []int{2, 4, 5}) {
+		t.Errorf("Expected [2 4 5], got %v", result)
+	}
+	if result := Empty[int]().Debounce(time.Second).ToSlice(); len(result) != 0 {
+		t.Errorf("Expected empty result, got %v", result)
+	}
+}
+
+func TestSampleInterval(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	result := arrivals(clock, 0, 30, 90, 120, 350, 360).SampleInterval(100*time.Millisecond, WithClock(clock)).ToSlice()
+	if !equalSlices(result, []int{2, 3, 5}) {
+		t.Errorf("Expected [2 3 5], got %v", result)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	clock := NewVirtualClock(time.Unix(0, 0))
+	s := Of("a", "b").Delay(time.Second, WithClock(clock))
+	if c := s.Characteristics(); !c.Has(Sized) || s.EstimateSize() != 2 {
+		t.Errorf("Expected Delay to keep the exact size, got %s", c.Flags)
+	}
+	if result := s.ToSlice(); !equalSlices(result, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", result)
+	}
+	if elapsed := clock.Now().Sub(time.Unix(0, 0)); elapsed != 2*time.Second {
+		t.Errorf("Expected 2s, got %s", elapsed)
+	}
+}
+
+func TestTimingContextCancelsWait(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	s := Of(1, 2, 3).RateLimit(1, time.Hour, WithContext(ctx))
+	if result := s.ToSlice(); !equalSlices(result, []int{1}) {
+		t.Errorf("Expected [1], got %v", result)
+	}
+	if !errors.Is(s.Err(), context.DeadlineExceeded) || time.Since(started) > time.Second {
+		t.Errorf("Expected the wait to be cancelled, got %v after %s", s.Err(), time.Since(started))
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	delayed := Of("a").Delay(time.Second, WithClock(NewVirtualClock(time.Unix(0, 0))), WithContext(cancelled))
+	if result := delayed.ToSlice(); len(result) != 0 || !errors.Is(delayed.Err(), context.Canceled) {
+		t.Errorf("Expected no elements and context.Canceled, got %v (%v)", result, delayed.Err())
+	}
+}
@@ -15,3 +15,15 @@ type Supplier[T any] func() T
 type BinaryOperator[T any] func(T, T) T
 
 type Comparator[T any] func(T, T) int
+
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+type Float interface {
+	~float32 | ~float64
+}
+
+type Number interface {
+	Integer | Float
+}
@@ -0,0 +1,183 @@
+package stream
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// FileEntry 是 WalkDir 产生的目录项，Path 是相对于 fs.FS 根的斜杠分隔路径，Depth 是相对于遍历起点的层级（从 1 开始）
+type FileEntry struct {
+	Path  string
+	Depth int
+	fs.DirEntry
+}
+
+// Size 返回文件大小，无法获取文件信息时返回 0
+func (e FileEntry) Size() int64 {
+	info, err := e.Info()
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+type WalkOption func(*walkConfig)
+
+type walkConfig struct {
+	maxDepth       int
+	include        []func(string) bool
+	exclude        []func(string) bool
+	followSymlinks bool
+	skipDir        func(FileEntry) bool
+}
+
+// WithMaxDepth 限制遍历深度，1 表示只列出起点目录下的直接子项；<= 0 表示不限制
+func WithMaxDepth(depth int) WalkOption {
+	return func(c *walkConfig) {
+		c.maxDepth = depth
+	}
+}
+
+// WithInclude 只输出匹配任一 glob 模式的目录项（不影响向下遍历）；不含 "/" 的模式只匹配文件名
+func WithInclude(patterns ...string) WalkOption {
+	return func(c *walkConfig) {
+		for _, pattern := range patterns {
+			c.include = append(c.include, globMatcher(pattern))
+		}
+	}
+}
+
+// WithExclude 跳过匹配任一 glob 模式的目录项，匹配的目录不会被向下遍历
+func WithExclude(patterns ...string) WalkOption {
+	return func(c *walkConfig) {
+		for _, pattern := range patterns {
+			c.exclude = append(c.exclude, globMatcher(pattern))
+		}
+	}
+}
+
+// WithIncludeRegexp 只输出路径匹配 re 的目录项
+func WithIncludeRegexp(re *regexp.Regexp) WalkOption {
+	return func(c *walkConfig) {
+		c.include = append(c.include, re.MatchString)
+	}
+}
+
+// WithExcludeRegexp 跳过路径匹配 re 的目录项
+func WithExcludeRegexp(re *regexp.Regexp) WalkOption {
+	return func(c *walkConfig) {
+		c.exclude = append(c.exclude, re.MatchString)
+	}
+}
+
+// WithFollowSymlinks 进入指向目录的符号链接，已经在当前路径上访问过的目录不会重复进入
+func WithFollowSymlinks() WalkOption {
+	return func(c *walkConfig) {
+		c.followSymlinks = true
+	}
+}
+
+// WithSkipDir 对返回 true 的目录不再向下遍历，目录本身仍会输出
+func WithSkipDir(predicate func(FileEntry) bool) WalkOption {
+	return func(c *walkConfig) {
+		c.skipDir = predicate
+	}
+}
+
+func globMatcher(pattern string) func(string) bool {
+	return func(p string) bool {
+		if !strings.Contains(pattern, "/") {
+			p = path.Base(p)
+		}
+		matched, _ := path.Match(pattern, p)
+		return matched
+	}
+}
+
+func matchesAny(matchers []func(string) bool, p string) bool {
+	for _, match := range matchers {
+		if match(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// walkFrame 是深度优先遍历中一个尚未处理完的目录
+type walkFrame struct {
+	entries []fs.DirEntry
+	dir     string
+	depth   int
+	info    fs.FileInfo
+	parent  *walkFrame
+}
+
+// visited 判断目录是否已在当前遍历路径上出现，用于避免符号链接造成的循环
+func (f *walkFrame) visited(info fs.FileInfo) bool {
+	for frame := f; frame != nil; frame = frame.parent {
+		if frame.info != nil && os.SameFile(frame.info, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// WalkDir 惰性地深度优先遍历 fsys 中 root 下的目录项（不包括 root 本身），同一目录中的项按文件名排序
+func WalkDir(fsys fs.FS, root string, opts ...WalkOption) Stream[FileEntry] {
+	cfg := walkConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return newLazyStream("WalkDir", func(s *streamImpl[FileEntry]) iterator[FileEntry] {
+		entries, err := fs.ReadDir(fsys, root)
+		if err != nil {
+			s.fail(err)
+			return emptyIterator[FileEntry]()
+		}
+		rootInfo, _ := fs.Stat(fsys, root)
+		top := &walkFrame{entries: entries, dir: root, depth: 1, info: rootInfo}
+
+		return func() (FileEntry, bool) {
+			for top != nil {
+				if len(top.entries) == 0 {
+					top = top.parent
+					continue
+				}
+				entry := top.entries[0]
+				top.entries = top.entries[1:]
+
+				item := FileEntry{Path: path.Join(top.dir, entry.Name()), Depth: top.depth, DirEntry: entry}
+				if matchesAny(cfg.exclude, item.Path) {
+					continue
+				}
+
+				descend, info := entry.IsDir(), fs.FileInfo(nil)
+				if cfg.followSymlinks && entry.Type()&fs.ModeSymlink != 0 {
+					if target, err := fs.Stat(fsys, item.Path); err == nil && target.IsDir() {
+						descend, info = !top.visited(target), target
+					}
+				}
+				if descend && (cfg.maxDepth <= 0 || top.depth < cfg.maxDepth) && (cfg.skipDir == nil || !cfg.skipDir(item)) {
+					children, err := fs.ReadDir(fsys, item.Path)
+					if err != nil {
+						s.fail(err)
+						return FileEntry{}, false
+					}
+					if info == nil {
+						info, _ = entry.Info()
+					}
+					top = &walkFrame{entries: children, dir: item.Path, depth: top.depth + 1, info: info, parent: top}
+				}
+
+				if len(cfg.include) == 0 || matchesAny(cfg.include, item.Path) {
+					return item, true
+				}
+			}
+			return FileEntry{}, false
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package stream
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"testing/fstest"
+)
+
+func newTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"go.mod":                     {Data: []byte("module x")},
+		"cmd/app/main.go":            {Data: []byte("package main")},
+		"internal/util/util.go":      {Data: []byte("package util\n")},
+		"internal/util/util_test.go": {Data: []byte("package util")},
+		"vendor/dep/dep.go":          {Data: []byte("package dep")},
+		"README.md":                  {Data: []byte("# readme")},
+	}
+}
+
+func TestWalkDir(t *testing.T) {
+	files := WalkDir(newTestFS(), ".").
+		Filter(func(e FileEntry) bool { return !e.IsDir() }).
+		ToSlice()
+
+	if len(files) != 6 {
+		t.Errorf("Expected 6 files, got %d", len(files))
+	}
+	if files[0].Path != "README.md" || files[1].Path != "cmd/app/main.go" {
+		t.Errorf("Expected depth-first lexical order, got %s, %s", files[0].Path, files[1].Path)
+	}
+}
+
+func TestWalkDirOptions(t *testing.T) {
+	var paths []string
+	WalkDir(newTestFS(), ".",
+		WithInclude("*.go"),
+		WithExclude("vendor", "*_test.go"),
+	).ForEach(func(e FileEntry) { paths = append(paths, e.Path) })
+
+	expected := []string{"cmd/app/main.go", "internal/util/util.go"}
+	if !equalSlices(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	paths = nil
+	WalkDir(newTestFS(), ".", WithMaxDepth(1)).ForEach(func(e FileEntry) { paths = append(paths, e.Path) })
+	expected = []string{"README.md", "cmd", "go.mod", "internal", "vendor"}
+	if !equalSlices(paths, expected) {
+		t.Errorf("Expected %v, got %v", expected, paths)
+	}
+
+	count := WalkDir(newTestFS(), ".",
+		WithIncludeRegexp(regexp.MustCompile(`\.go$`)),
+		WithSkipDir(func(e FileEntry) bool { return e.Name() == "internal" }),
+	).Count()
+	if count != 2 {
+		t.Errorf("Expected 2 go files outside internal, got %d", count)
+	}
+}
+
+func TestWalkDirSizes(t *testing.T) {
+	total := WalkDir(newTestFS(), "internal").
+		Filter(func(e FileEntry) bool { return !e.IsDir() }).
+		Collect(Summing(func(e FileEntry) int64 { return e.Size() }))
+
+	if total != int64(25) {
+		t.Errorf("Expected 25 bytes, got %v", total)
+	}
+}
+
+func TestWalkDirFollowSymlinks(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "real", "sub"), 0o755)
+	os.WriteFile(filepath.Join(root, "real", "sub", "a.txt"), []byte("a"), 0o644)
+	if err := os.Symlink(filepath.Join(root, "real"), filepath.Join(root, "link")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	// 指回祖先目录的链接不能导致无限循环
+	os.Symlink(root, filepath.Join(root, "real", "loop"))
+
+	count := func(opts ...WalkOption) int64 {
+		return WalkDir(os.DirFS(root), ".", append(opts, WithInclude("a.txt"))...).Count()
+	}
+	if n := count(); n != 1 {
+		t.Errorf("Expected 1 file without following symlinks, got %d", n)
+	}
+	if n := count(WithFollowSymlinks()); n != 2 {
+		t.Errorf("Expected 2 files when following symlinks, got %d", n)
+	}
+}
+
+func TestWalkDirMissingRoot(t *testing.T) {
+	s := WalkDir(newTestFS(), "missing")
+	if s.Count() != 0 || s.Err() == nil {
+		t.Errorf("Expected error for missing root")
+	}
+}
@@ -0,0 +1,223 @@
+package stream
+
+import (
+	"sort"
+	"time"
+)
+
+// Window 是一个事件时间窗口的聚合结果，窗口为 [Start, End)
+// Result 为 collector 对窗口中元素的收集结果，与 Collect 一样需要类型断言
+type Window struct {
+	Start  time.Time
+	End    time.Time
+	Count  int
+	Result any
+	// Update 为 true 表示窗口已经产生过结果，这是因为迟到元素而重新产生的结果，应当替换之前的结果
+	Update bool
+}
+
+// WindowOption 配置 TumblingWindow、SlidingWindow 和 SessionWindow 的水位线和迟到元素的处理
+type WindowOption func(*windowConfig)
+
+type windowConfig struct {
+	outOfOrderness time.Duration
+	lateness       time.Duration
+	onLate         func(element any, timestamp time.Time)
+}
+
+// WithOutOfOrderness 设置水位线落后于已见到的最大时间戳的时间，即允许元素乱序到达的程度，默认为 0
+func WithOutOfOrderness(d time.Duration) WindowOption {
+	return func(c *windowConfig) {
+		c.outOfOrderness = d
+	}
+}
+
+// WithAllowedLateness 设置窗口产生结果后继续保留的时间：期间到达的迟到元素会使窗口以 Update 重新产生结果，默认为 0
+func WithAllowedLateness(d time.Duration) WindowOption {
+	return func(c *windowConfig) {
+		c.lateness = d
+	}
+}
+
+// OnLateElement 设置超过允许迟到时间而被丢弃的元素的处理函数
+func OnLateElement(handler func(element any, timestamp time.Time)) WindowOption {
+	return func(c *windowConfig) {
+		c.onLate = handler
+	}
+}
+
+// TumblingWindow 按事件时间把元素分到长度为 size、互不重叠的窗口中（窗口边界与 time.Time 的 Truncate 对齐），
+// 并对每个窗口的元素应用 collector
+func TumblingWindow[T any](s Stream[T], size time.Duration, timestamp func(T) time.Time, collector Collector[T, any, any], opts ...WindowOption) Stream[Window] {
+	return SlidingWindow[T](s, size, size, timestamp, collector, opts...)
+}
+
+// SlidingWindow 按事件时间把元素分到长度为 size、每隔 slide 开始一个的窗口中，一个元素可能属于多个窗口
+func SlidingWindow[T any](s Stream[T], size, slide time.Duration, timestamp func(T) time.Time, collector Collector[T, any, any], opts ...WindowOption) Stream[Window] {
+	size, slide = max(size, 1), max(slide, 1)
+	name := "SlidingWindow"
+	if size == slide {
+		name = "TumblingWindow"
+	}
+	return windowed[T](name, s, timestamp, collector, opts, func(w *windower[T], item T, ts time.Time) bool {
+		// size 小于 slide 时窗口之间有间隙，落在间隙中的元素不属于任何窗口，被忽略但不是迟到元素
+		covered, accepted := false, false
+		for start := ts.Truncate(slide); start.Add(size).After(ts); start = start.Add(-slide) {
+			covered = true
+			accepted = w.add(start, start.Add(size), item) || accepted
+		}
+		return covered && !accepted
+	})
+}
+
+// SessionWindow 按事件时间把间隔不超过 gap 的元素分到同一个会话中，会话窗口为 [最早时间戳, 最晚时间戳+gap)
+func SessionWindow[T any](s Stream[T], gap time.Duration, timestamp func(T) time.Time, collector Collector[T, any, any], opts ...WindowOption) Stream[Window] {
+	return windowed[T]("SessionWindow", s, timestamp, collector, opts, func(w *windower[T], item T, ts time.Time) bool {
+		return !w.merge(ts, ts.Add(gap), item)
+	})
+}
+
+type windowState[T any] struct {
+	start, end time.Time
+	items      []T
+	fired      bool
+	// dirty 表示窗口有尚未产生结果的元素
+	dirty bool
+}
+
+// windower 按水位线管理打开的窗口：水位线为已见到的最大时间戳减去允许乱序的时间，
+// 结束时间不晚于水位线的窗口产生结果，结束时间加上允许迟到时间不晚于水位线的窗口被丢弃
+type windower[T any] struct {
+	cfg       windowConfig
+	windows   []*windowState[T]
+	watermark time.Time
+	started   bool
+}
+
+func (w *windower[T]) expired(end time.Time) bool {
+	return w.started && !end.Add(w.cfg.lateness).After(w.watermark)
+}
+
+// add 把元素加入窗口 [start, end)，窗口已经过期时返回 false
+func (w *windower[T]) add(start, end time.Time, item T) bool {
+	if w.expired(end) {
+		return false
+	}
+	for _, state := range w.windows {
+		if state.start.Equal(start) && state.end.Equal(end) {
+			state.items = append(state.items, item)
+			state.dirty = true
+			return true
+		}
+	}
+	w.windows = append(w.windows, &windowState[T]{start: start, end: end, items: []T{item}, dirty: true})
+	return true
+}
+
+// merge 把元素所在的会话 [start, end) 与相交的会话合并
+func (w *windower[T]) merge(start, end time.Time, item T) bool {
+	merged := &windowState[T]{start: start, end: end, items: []T{item}, dirty: true}
+	kept := w.windows[:0]
+	for _, state := range w.windows {
+		if state.start.After(merged.end) || merged.start.After(state.end) {
+			kept = append(kept, state)
+			continue
+		}
+		merged.start = minTime(merged.start, state.start)
+		merged.end = maxTime(merged.end, state.end)
+		merged.items = append(state.items, merged.items...)
+		merged.fired = merged.fired || state.fired
+	}
+	w.windows = kept
+	if len(merged.items) == 1 && w.expired(end) {
+		return false
+	}
+	w.windows = append(w.windows, merged)
+	return true
+}
+
+// advance 推进水位线，返回按结束时间排序的可以产生结果的窗口，并丢弃过期的窗口
+func (w *windower[T]) advance(watermark time.Time, final bool) []*windowState[T] {
+	if !w.started || watermark.After(w.watermark) {
+		w.watermark, w.started = watermark, true
+	}
+	var ready []*windowState[T]
+	kept := w.windows[:0]
+	for _, state := range w.windows {
+		if state.dirty && (final || !state.end.After(w.watermark)) {
+			ready = append(ready, state)
+		}
+		if final || !w.expired(state.end) {
+			kept = append(kept, state)
+		}
+	}
+	w.windows = kept
+	sort.SliceStable(ready, func(i, j int) bool {
+		if !ready[i].end.Equal(ready[j].end) {
+			return ready[i].end.Before(ready[j].end)
+		}
+		return ready[i].start.Before(ready[j].start)
+	})
+	return ready
+}
+
+// windowed 创建窗口流，assign 把元素加入它所属的窗口，元素所属的窗口都已过期（即元素迟到）时返回 true
+func windowed[T any](name string, s Stream[T], timestamp func(T) time.Time, collector Collector[T, any, any], opts []WindowOption,
+	assign func(w *windower[T], item T, ts time.Time) bool) Stream[Window] {
+	var cfg windowConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return derive(name, s, func(_ *streamImpl[Window], upstream iterator[T]) iterator[Window] {
+		w := &windower[T]{cfg: cfg}
+		var pending []Window
+		exhausted := false
+		emit := func(ready []*windowState[T]) {
+			for _, state := range ready {
+				pending = append(pending, Window{
+					Start:  state.start,
+					End:    state.end,
+					Count:  len(state.items),
+					Result: collector.Collect(state.items),
+					Update: state.fired,
+				})
+				state.fired, state.dirty = true, false
+			}
+		}
+		return func() (Window, bool) {
+			for len(pending) == 0 && !exhausted {
+				item, ok := upstream()
+				if !ok {
+					exhausted = true
+					emit(w.advance(w.watermark, true))
+					break
+				}
+				ts := timestamp(item)
+				if assign(w, item, ts) && cfg.onLate != nil {
+					cfg.onLate(item, ts)
+				}
+				emit(w.advance(ts.Add(-cfg.outOfOrderness), false))
+			}
+			if len(pending) == 0 {
+				return Window{}, false
+			}
+			next := pending[0]
+			pending = pending[1:]
+			return next, true
+		}
+	})
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,121 @@
+package stream
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+type event struct {
+	at   time.Time
+	user string
+}
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// events 产生在 epoch 之后 seconds[i] 秒发生的事件
+func events(user string, seconds ...int) []event {
+	result := make([]event, len(seconds))
+	for i, s := range seconds {
+		result[i] = event{at: epoch.Add(time.Duration(s) * time.Second), user: user}
+	}
+	return result
+}
+
+func eventTime(e event) time.Time { return e.at }
+
+// describe 把窗口格式化为 "开始秒-结束秒:元素个数"，更新的窗口以 * 结尾
+func describe(windows []Window) []string {
+	result := make([]string, len(windows))
+	for i, w := range windows {
+		result[i] = fmt.Sprintf("%d-%d:%d", int(w.Start.Sub(epoch).Seconds()), int(w.End.Sub(epoch).Seconds()), w.Count)
+		if w.Update {
+			result[i] += "*"
+		}
+	}
+	return result
+}
+
+func TestTumblingWindow(t *testing.T) {
+	windows := TumblingWindow(OfSlice(events("a", 0, 10, 59, 60, 61, 185)), time.Minute, eventTime, Counting[event]()).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"0-60:3", "60-120:2", "180-240:1"}) {
+		t.Errorf("Unexpected windows: %v", got)
+	}
+	if windows[0].Result.(int64) != 3 {
+		t.Errorf("Expected count 3 from collector, got %d", windows[0].Result)
+	}
+}
+
+func TestTumblingWindowIsIncremental(t *testing.T) {
+	pulled := 0
+	s := OfSlice(events("a", 0, 30, 60, 90, 120, 150)).Peek(func(event) { pulled++ })
+	first := TumblingWindow(s, time.Minute, eventTime, ToSlice[event]()).FindFirst().Get()
+	if first.Count != 2 || pulled != 3 {
+		t.Errorf("Expected first window after 3 elements, got %d elements after %d", first.Count, pulled)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	windows := SlidingWindow(OfSlice(events("a", 0, 10, 20)), 20*time.Second, 10*time.Second, eventTime, Counting[event]()).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"-10-10:1", "0-20:2", "10-30:2", "20-40:1"}) {
+		t.Errorf("Unexpected windows: %v", got)
+	}
+
+	// 窗口之间有间隙时，落在间隙中的元素被忽略，不是迟到元素
+	var late []time.Time
+	onLate := OnLateElement(func(_ any, ts time.Time) { late = append(late, ts) })
+	windows = SlidingWindow(OfSlice(events("a", 1, 3, 6)), 2*time.Second, 5*time.Second, eventTime, Counting[event](), onLate).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"0-2:1", "5-7:1"}) {
+		t.Errorf("Unexpected windows with gaps: %v", got)
+	}
+	if len(late) != 0 {
+		t.Errorf("Expected no late elements, got %v", late)
+	}
+}
+
+func TestSessionWindow(t *testing.T) {
+	input := append(events("a", 0, 20, 45), events("b", 200, 210)...)
+	windows := SessionWindow(OfSlice(input), 30*time.Second, eventTime, JoiningWithMapper(func(e event) string { return e.user }, "")).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"0-75:3", "200-240:2"}) {
+		t.Errorf("Unexpected windows: %v", got)
+	}
+	if windows[1].Result.(string) != "bb" {
+		t.Errorf("Expected bb, got %q", windows[1].Result)
+	}
+
+	// 乱序到达的元素把两个会话连接起来
+	bridged := events("a", 0, 60, 30)
+	windows = SessionWindow(OfSlice(bridged), 30*time.Second, eventTime, Counting[event](), WithOutOfOrderness(time.Minute)).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"0-90:3"}) {
+		t.Errorf("Unexpected windows: %v", got)
+	}
+}
+
+func TestWindowWatermarkAndLateness(t *testing.T) {
+	// 70 秒的元素让水位线越过 60 秒，0-60 窗口产生结果；之后到达的 50 秒的元素是迟到元素
+	input := events("a", 10, 70, 50, 130, 40, 200)
+
+	var late []time.Time
+	onLate := OnLateElement(func(_ any, ts time.Time) { late = append(late, ts) })
+	windows := TumblingWindow(OfSlice(input), time.Minute, eventTime, Counting[event](), onLate).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"0-60:1", "60-120:1", "120-180:1", "180-240:1"}) {
+		t.Errorf("Unexpected windows without lateness: %v", got)
+	}
+	if len(late) != 2 {
+		t.Errorf("Expected 2 late elements, got %v", late)
+	}
+
+	windows = TumblingWindow(OfSlice(input), time.Minute, eventTime, Counting[event](), WithOutOfOrderness(15*time.Second)).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"0-60:2", "60-120:1", "120-180:1", "180-240:1"}) {
+		t.Errorf("Unexpected windows with out-of-orderness: %v", got)
+	}
+
+	late = nil
+	windows = TumblingWindow(OfSlice(input), time.Minute, eventTime, Counting[event](), WithAllowedLateness(time.Minute), onLate).ToSlice()
+	if got := describe(windows); !equalSlices(got, []string{"0-60:1", "0-60:2*", "60-120:1", "120-180:1", "180-240:1"}) {
+		t.Errorf("Unexpected windows with allowed lateness: %v", got)
+	}
+	if len(late) != 1 || !late[0].Equal(epoch.Add(40*time.Second)) {
+		t.Errorf("Expected the element at 40s to be dropped, got %v", late)
+	}
+}
@@ -0,0 +1,172 @@
+package stream
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteTo 将每个元素格式化后作为一行写入 w，返回写入的字节数；formatter 为 nil 时使用 fmt.Sprint
+func WriteTo[T any](s Stream[T], w io.Writer, formatter Function[T, string]) (int64, error) {
+	if formatter == nil {
+		formatter = func(item T) string {
+			return fmt.Sprint(item)
+		}
+	}
+	counter := &countingWriter{writer: w}
+	buffered := bufio.NewWriter(counter)
+
+	it := s.iterate()
+	defer s.close()
+	for item, ok := it(); ok; item, ok = it() {
+		if _, err := buffered.WriteString(formatter(item)); err != nil {
+			return counter.written, err
+		}
+		if err := buffered.WriteByte('\n'); err != nil {
+			return counter.written, err
+		}
+	}
+	if err := buffered.Flush(); err != nil {
+		return counter.written, err
+	}
+	return counter.written, s.Err()
+}
+
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+type TableOption func(*tableConfig)
+
+type tableConfig struct {
+	columns  []string
+	markdown bool
+}
+
+// WithColumns 选择并排序要输出的列，列名为 `table` 标签或字段名
+func WithColumns(columns ...string) TableOption {
+	return func(c *tableConfig) {
+		c.columns = columns
+	}
+}
+
+// WithMarkdown 输出 Markdown 表格而不是对齐的纯文本表格
+func WithMarkdown() TableOption {
+	return func(c *tableConfig) {
+		c.markdown = true
+	}
+}
+
+// ToTable 将结构体流渲染为表格写入 w，表头取自 `table` 标签或字段名；非结构体元素输出为单列 Value
+func ToTable[T any](s Stream[T], w io.Writer, opts ...TableOption) error {
+	cfg := tableConfig{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	fields, err := tableFields(reflect.TypeOf((*T)(nil)).Elem(), cfg.columns)
+	if err != nil {
+		return err
+	}
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = f.name
+	}
+
+	var out io.Writer
+	var flush func() error
+	if cfg.markdown {
+		buffered := bufio.NewWriter(w)
+		out, flush = buffered, buffered.Flush
+	} else {
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		out, flush = tw, tw.Flush
+	}
+	writeRow := func(cells []string) error {
+		var err error
+		if cfg.markdown {
+			_, err = fmt.Fprintf(out, "| %s |\n", strings.Join(cells, " | "))
+		} else {
+			_, err = fmt.Fprintln(out, strings.Join(cells, "\t"))
+		}
+		return err
+	}
+
+	if err := writeRow(header); err != nil {
+		return err
+	}
+	if cfg.markdown {
+		separator := make([]string, len(header))
+		for i := range separator {
+			separator[i] = "---"
+		}
+		if err := writeRow(separator); err != nil {
+			return err
+		}
+	}
+
+	it := s.iterate()
+	defer s.close()
+	cells := make([]string, len(fields))
+	for item, ok := it(); ok; item, ok = it() {
+		value := reflect.ValueOf(&item).Elem()
+		for i, f := range fields {
+			if f.index == nil {
+				cells[i] = formatCell(value, cfg.markdown)
+			} else {
+				cells[i] = formatCell(value.FieldByIndex(f.index), cfg.markdown)
+			}
+		}
+		if err := writeRow(cells); err != nil {
+			return err
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+	return s.Err()
+}
+
+// tableFields 返回要输出的列；非结构体类型输出为 index 为 nil 的单列
+func tableFields(t reflect.Type, columns []string) ([]structField, error) {
+	if t.Kind() != reflect.Struct {
+		return []structField{{name: "Value"}}, nil
+	}
+	fields, err := structFields(t, "table")
+	if err != nil || len(columns) == 0 {
+		return fields, err
+	}
+	selected := make([]structField, 0, len(columns))
+	for _, column := range columns {
+		f, ok := lookupField(fields, column)
+		if !ok {
+			return nil, fmt.Errorf("stream: unknown table column %q", column)
+		}
+		selected = append(selected, f)
+	}
+	return selected, nil
+}
+
+// formatCell 格式化单元格，并替换会破坏表格结构的字符
+func formatCell(v reflect.Value, markdown bool) string {
+	text, err := formatValue(v, time.RFC3339)
+	if err != nil {
+		text = fmt.Sprint(v.Interface())
+	}
+	if markdown {
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(text)
+	}
+	return strings.NewReplacer("\t", " ", "\n", " ").Replace(text)
+}
@@ -0,0 +1,92 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type tableRow struct {
+	Name    string
+	Latency float64 `table:"Latency (ms)"`
+	Errors  int
+	secret  string
+}
+
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := WriteTo(Of(1, 2, 3), &buf, func(n int) string { return IntToString(int64(n * n)) })
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "1\n4\n9\n" || n != 6 {
+		t.Errorf("Unexpected output %q (%d bytes)", buf.String(), n)
+	}
+
+	buf.Reset()
+	WriteTo(Of("a", "b"), &buf, nil)
+	if buf.String() != "a\nb\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestWriteToError(t *testing.T) {
+	_, err := WriteTo(Of(1, 2, 3), failingWriter{}, nil)
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("Expected write error, got %v", err)
+	}
+}
+
+func TestToTable(t *testing.T) {
+	rows := []tableRow{
+		{Name: "/api/users", Latency: 12.5, Errors: 0},
+		{Name: "/health", Latency: 1, Errors: 3},
+	}
+
+	var buf bytes.Buffer
+	if err := ToTable(OfSlice(rows), &buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"Name        Latency (ms)  Errors\n" +
+		"/api/users  12.5          0\n" +
+		"/health     1             3\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestToTableMarkdown(t *testing.T) {
+	rows := []tableRow{{Name: "a|b", Latency: 2, Errors: 1}}
+
+	var buf bytes.Buffer
+	err := ToTable(OfSlice(rows), &buf, WithMarkdown(), WithColumns("Errors", "Name"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "| Errors | Name |\n| --- | --- |\n| 1 | a\\|b |\n"
+	if buf.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, buf.String())
+	}
+
+	err = ToTable(OfSlice(rows), &buf, WithColumns("Missing"))
+	if err == nil || !strings.Contains(err.Error(), "Missing") {
+		t.Errorf("Expected unknown column error, got %v", err)
+	}
+}
+
+func TestToTableScalar(t *testing.T) {
+	var buf bytes.Buffer
+	ToTable(Of(1, 2), &buf)
+	if buf.String() != "Value\n1\n2\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+}